@@ -0,0 +1,191 @@
+package logh
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the current state of a Logger, as returned by Logger.Status and AdminHandler.
+type Status struct {
+	Name     string   `json:"name"`
+	Level    string   `json:"level"`
+	Levels   []string `json:"levels"`
+	Path     string   `json:"path"`
+	Rotation int      `json:"rotation"`
+	// Size is the size of the current rotation file; 0 when logging to STDOUT.
+	Size int64 `json:"size"`
+}
+
+// adminHandler implements AdminHandler.
+type adminHandler struct {
+	registry *Registry
+
+	mu sync.Mutex
+	// reverts holds pending TTL reverts, keyed by Logger.
+	reverts map[*Logger]*levelRevert
+}
+
+// levelRevert is a pending revert to level.
+type levelRevert struct {
+	level LoghLevel
+	timer *time.Timer
+}
+
+// AdminHandler returns a http.Handler to inspect and change logger levels at runtime.
+//
+//	GET  lists every logger in Map as a JSON array of Status, sorted by name.
+//	PUT  ?name=app&level=debug[&ttl=5m] sets the level of a logger, returning its Status.
+//	     With ttl, the level reverts to the level before the first unexpired PUT after ttl.
+//
+// The handler has no authentication; serve it only on an administrative listener.
+func AdminHandler() http.Handler {
+	return defaultRegistry.AdminHandler()
+}
+
+// AdminHandler is AdminHandler for the loggers in r.
+func (r *Registry) AdminHandler() http.Handler {
+	return &adminHandler{registry: r, reverts: map[*Logger]*levelRevert{}}
+}
+
+// Level returns the current level of l; -1 for a nil or discard Logger.
+func (l *Logger) Level() LoghLevel {
+	if l == nil || l.discard {
+		return -1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// HighestLevel returns the highest of the levels of l, the level Fatalf and Panicf write
+// at; -1 for a nil or discard Logger.
+func (l *Logger) HighestLevel() LoghLevel {
+	if l == nil || l.discard {
+		return -1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LoghLevel(len(l.levels) - 1)
+}
+
+// SetLevel changes the level of l. Entries being written concurrently complete with the
+// prior level.
+func (l *Logger) SetLevel(level LoghLevel) error {
+	if l == nil {
+		return fmt.Errorf("nil Logger")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < 0 || int(level) >= len(l.levels) {
+		return fmt.Errorf("input level was outside range, level:%d, len(levels)-1:%d", level, len(l.levels)-1)
+	}
+	l.level = level
+	return nil
+}
+
+// Status returns the current state of l. name is not known to the Logger, and is copied
+// to the result.
+func (l *Logger) Status(name string) Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := Status{
+		Name:     name,
+		Levels:   append([]string(nil), l.levels...),
+		Path:     l.filePath,
+		Rotation: l.rotation,
+	}
+	if int(l.level) < len(l.levels) {
+		s.Level = l.levels[l.level]
+	}
+	if l.file != nil {
+		s.Size = l.size
+	}
+	return s
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		loggers := h.registry.loggersSnapshot()
+		statuses := make([]Status, 0, len(loggers))
+		for name, l := range loggers {
+			statuses = append(statuses, l.Status(name))
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+		writeJSON(w, statuses)
+	case http.MethodPut:
+		h.put(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *adminHandler) put(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := q.Get("name")
+	h.registry.mu.RLock()
+	l := h.registry.loggers[name]
+	h.registry.mu.RUnlock()
+	if l == nil {
+		http.Error(w, fmt.Sprintf("unknown logger:%s", name), http.StatusNotFound)
+		return
+	}
+
+	level, err := l.ParseLevel(q.Get("level"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var ttl time.Duration
+	if v := q.Get("ttl"); v != "" {
+		if ttl, err = time.ParseDuration(v); err != nil || ttl <= 0 {
+			http.Error(w, fmt.Sprintf("invalid ttl:%s", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	h.mu.Lock()
+	pending := h.reverts[l]
+	if pending != nil {
+		pending.timer.Stop()
+		delete(h.reverts, l)
+	}
+	if ttl > 0 {
+		revert := &levelRevert{level: l.Level()}
+		if pending != nil {
+			revert.level = pending.level
+		}
+		revert.timer = time.AfterFunc(ttl, func() { h.revert(l, revert) })
+		h.reverts[l] = revert
+	}
+	err = l.SetLevel(level)
+	h.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, l.Status(name))
+}
+
+// revert restores the level saved in revert, unless a later PUT replaced it.
+func (h *adminHandler) revert(l *Logger, revert *levelRevert) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.reverts[l] != revert {
+		return
+	}
+	delete(h.reverts, l)
+	l.SetLevel(revert.level)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,81 @@
+package logh
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestAdminHandler tests listing loggers, changing a level, and reverting after a TTL.
+func TestAdminHandler(t *testing.T) {
+	adminLog := filepath.Join(t.TempDir(), "admin.txt")
+	ShutdownAll()
+	defer ShutdownAll()
+	if err := New("admin.b", adminLog, DefaultLevels, Warning, 0, 10, 10000); err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	if err := New("admin.a", "", DefaultLevels, Info, 0, 10, 10000); err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	Map["admin.b"].Println(Error, "size")
+	h := AdminHandler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	var statuses []Status
+	if err := json.Unmarshal(w.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("error decoding, error: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "admin.a" || statuses[1].Name != "admin.b" ||
+		statuses[1].Level != "warning" || statuses[1].Path != adminLog || statuses[1].Size != 12 ||
+		len(statuses[1].Levels) != len(DefaultLevels) {
+		t.Errorf("incorrect list: %+v", statuses)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/?name=admin.b&level=debug&ttl=20ms", nil))
+	if w.Code != http.StatusOK || Map["admin.b"].Level() != Debug {
+		t.Errorf("level not set, code: %d, body: %s", w.Code, w.Body.String())
+	}
+	// A second PUT before the TTL expires keeps the original level to revert to.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/?name=admin.b&level=info&ttl=20ms", nil))
+	waitFor(t, func() bool { return Map["admin.b"].Level() == Warning })
+
+	// A PUT without a TTL cancels a pending revert.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/?name=admin.a&level=audit&ttl=10ms", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/?name=admin.a&level=error", nil))
+	time.Sleep(30 * time.Millisecond)
+	if Map["admin.a"].Level() != Error {
+		t.Errorf("permanent level was reverted, level: %d", Map["admin.a"].Level())
+	}
+
+	for _, v := range []struct {
+		method string
+		target string
+		code   int
+	}{
+		{http.MethodPut, "/?name=missing&level=debug", http.StatusNotFound},
+		{http.MethodPut, "/?name=admin.a&level=verbose", http.StatusBadRequest},
+		{http.MethodPut, "/?name=admin.a&level=debug&ttl=soon", http.StatusBadRequest},
+		{http.MethodDelete, "/", http.StatusMethodNotAllowed},
+	} {
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(v.method, v.target, nil))
+		if w.Code != v.code {
+			t.Errorf("incorrect code, method: %s, target: %s, code: %d", v.method, v.target, w.Code)
+		}
+	}
+}
+
+// TestLevelNil tests that Level and HighestLevel return -1 for nil and discard Loggers.
+func TestLevelNil(t *testing.T) {
+	var l *Logger
+	for _, lg := range []*Logger{l, Get("missing")} {
+		if lg.Level() != -1 || lg.HighestLevel() != -1 {
+			t.Errorf("incorrect levels, level: %d, highest: %d", lg.Level(), lg.HighestLevel())
+		}
+	}
+}
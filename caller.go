@@ -0,0 +1,114 @@
+package logh
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth is the most frames written by SetStackTrace.
+const maxStackDepth = 64
+
+// callerInfo holds the SetCallerInfo and SetStackTrace options.
+type callerInfo struct {
+	funcName    bool
+	goroutineID bool
+	stack       bool
+	stackLevel  LoghLevel
+}
+
+// SetCallerInfo adds the function name of the call site, as a func field, and the
+// goroutine ID, as a goroutine field, to each entry.
+//
+//	info: 2021/04/01 15:43:24.617769 db.go:42: connected func=example.com/app/db.Open goroutine=7
+func (l *Logger) SetCallerInfo(funcName, goroutineID bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c := l.callerOptions()
+	c.funcName, c.goroutineID = funcName, goroutineID
+	l.setCallerInfo(c)
+}
+
+// SetStackTrace appends the call stack, from the call site up, to entries at or above
+// level. Each frame is written as the function name, then a tab indented file:line, on
+// lines after the entry. enable false removes stack traces.
+func (l *Logger) SetStackTrace(enable bool, level LoghLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c := l.callerOptions()
+	c.stack, c.stackLevel = enable, level
+	l.setCallerInfo(c)
+}
+
+// callerOptions returns a copy of the caller options; l.mu must be held.
+func (l *Logger) callerOptions() callerInfo {
+	if l.callerInfo == nil {
+		return callerInfo{}
+	}
+	return *l.callerInfo
+}
+
+// setCallerInfo sets the caller options, or nil if none are enabled, so entries only pay
+// for caller lookups while an option is set; l.mu must be held.
+func (l *Logger) setCallerInfo(c callerInfo) {
+	if !c.funcName && !c.goroutineID && !c.stack {
+		l.callerInfo = nil
+		return
+	}
+	l.callerInfo = &c
+}
+
+// entry adds the caller information for the call site skip frames above entry to msg, the
+// formatted message and fields; skip 1 is the function calling entry. l.mu must be held.
+func (l *Logger) entry(skip int, level LoghLevel, msg string) string {
+	c := l.callerInfo
+	if c == nil {
+		return msg
+	}
+
+	var fields []Field
+	if c.funcName {
+		pcs := [1]uintptr{}
+		runtime.Callers(skip+1, pcs[:])
+		frame, _ := runtime.CallersFrames(pcs[:]).Next()
+		fields = append(fields, Field{"func", frame.Function})
+	}
+	if c.goroutineID {
+		fields = append(fields, Field{"goroutine", goroutineID()})
+	}
+	entry := msg + formatFields(fields)
+	if c.stack && level >= c.stackLevel {
+		entry += callStack(skip + 1)
+	}
+	return entry
+}
+
+// callStack returns the call stack from skip frames above callStack, one line for the
+// function and one for the file:line of each frame, each line preceded by a newline.
+func callStack(skip int) string {
+	pcs := make([]uintptr, maxStackDepth)
+	pcs = pcs[:runtime.Callers(skip+1, pcs)]
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		sb.WriteString("\n" + frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// "goroutine N [status]:" header of its stack trace.
+func goroutineID() uint64 {
+	buf := [64]byte{}
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
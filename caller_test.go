@@ -0,0 +1,61 @@
+package logh
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestCallerInfo tests that the function name and goroutine ID are added as fields.
+func TestCallerInfo(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, log.Lshortfile, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetCallerInfo(true, true)
+
+	_, _, line, _ := runtime.Caller(0)
+	lg.Printw(Info, "connected", "db", "main")
+	lg.SetCallerInfo(false, false)
+	lg.Println(Info, "plain")
+	lg.Shutdown()
+
+	expected := fmt.Sprintf("info: caller_test.go:%d: connected db=main func=github.com/paulfdunn/logh.TestCallerInfo goroutine=%d\n"+
+		"info: caller_test.go:%d: plain\n", line+1, goroutineID(), line+3)
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
+
+// TestStackTrace tests that the call stack is appended only at or above the stack level,
+// starting at the call site.
+func TestStackTrace(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, log.Lshortfile, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetStackTrace(true, Error)
+
+	_, file, line, _ := runtime.Caller(0)
+	lg.Println(Warning, "no stack")
+	lg.Println(Error, "with stack")
+	lg.Shutdown()
+
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	expected := fmt.Sprintf("warning: caller_test.go:%d: no stack\n"+
+		"error: caller_test.go:%d: with stack\n"+
+		"github.com/paulfdunn/logh.TestStackTrace\n\t%s:%d\n"+
+		"testing.tRunner\n", line+1, line+2, file, line+2)
+	if !strings.HasPrefix(logString, expected) {
+		t.Errorf("incorrect output, received:\n%s\nexpected prefix:\n%s", logString, expected)
+	}
+}
@@ -0,0 +1,48 @@
+package logh
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// Clock is the source of the current time for a Logger.
+type Clock interface {
+	Now() time.Time
+}
+
+// SetClock sets the time source for timestamps, sampling intervals, rate limit intervals
+// and the deduplication flush; nil restores time.Now. While a Clock is set, timestamps
+// from the log flags are formatted by l from the Clock, with the same layout, so tests
+// can set the time and compare entries byte for byte.
+func (l *Logger) SetClock(c Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clock = c
+	l.setTimeFormat(l.timeLayout, l.timeLocation)
+}
+
+// now returns the time from the Clock; l.mu must be held.
+func (l *Logger) now() time.Time {
+	if l.clock == nil {
+		return time.Now()
+	}
+	return l.clock.Now()
+}
+
+// flagsLayout returns the time.Format layout for the date and time log flags, as
+// written by the log package.
+func flagsLayout(flags int) string {
+	var layout []string
+	if flags&log.Ldate != 0 {
+		layout = append(layout, "2006/01/02")
+	}
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		if flags&log.Lmicroseconds != 0 {
+			layout = append(layout, "15:04:05.000000")
+		} else {
+			layout = append(layout, "15:04:05")
+		}
+	}
+	return strings.Join(layout, " ")
+}
@@ -0,0 +1,84 @@
+package logh
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// testClock is a Clock that only moves when advanced.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// TestClock tests that timestamps from the flags, rate limit intervals and the
+// deduplication flush follow the Clock, so the output can be compared byte for byte.
+func TestClock(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, DefaultFlags, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	clock := &testClock{now: time.Date(2021, 4, 1, 15, 43, 24, 617769000, time.UTC)}
+	lg.SetClock(clock)
+	lg.SetRateLimit(1, time.Minute)
+	lg.SetDeduplicate(true, time.Hour)
+
+	_, _, line, _ := runtime.Caller(0)
+	for i := 0; i < 4; i++ {
+		lg.Printf(Warning, "retry %d", i)
+		clock.advance(time.Second)
+		if i == 2 {
+			clock.advance(time.Minute)
+		}
+	}
+	lg.Println(Info, "down")
+	lg.Println(Info, "down")
+	clock.advance(time.Hour)
+	lg.Println(Info, "down")
+	lg.Shutdown()
+
+	expected := fmt.Sprintf("warning: 2021/04/01 15:43:24.617769 clock_test.go:%[1]d: retry 0\n"+
+		"warning: 2021/04/01 15:44:27.617769 clock_test.go:%[1]d: rate limited 2 entries in 1m0s\n"+
+		"warning: 2021/04/01 15:44:27.617769 clock_test.go:%[1]d: retry 3\n"+
+		"info: 2021/04/01 15:44:28.617769 clock_test.go:%[2]d: down\n"+
+		"info: 2021/04/01 16:44:28.617769 clock_test.go:%[3]d: last message repeated 1 times\n"+
+		"info: 2021/04/01 16:44:28.617769 clock_test.go:%[3]d: down\n",
+		line+2, line+8, line+11)
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
+
+// TestFlagsLayout tests that the layout matches the log package timestamps.
+func TestFlagsLayout(t *testing.T) {
+	tm := time.Date(2021, 4, 1, 15, 43, 24, 617769000, time.UTC)
+	tests := []struct {
+		flags    int
+		expected string
+	}{
+		{0, ""},
+		{log.Ldate, "2021/04/01"},
+		{log.Ltime, "15:43:24"},
+		{log.Lmicroseconds, "15:43:24.617769"},
+		{log.Ldate | log.Ltime | log.Lmicroseconds, "2021/04/01 15:43:24.617769"},
+	}
+	for _, v := range tests {
+		if s := tm.Format(flagsLayout(v.flags)); s != v.expected {
+			t.Errorf("incorrect layout, flags: %d, received: %s, expected: %s", v.flags, s, v.expected)
+		}
+	}
+}
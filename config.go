@@ -0,0 +1,247 @@
+package logh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config is the JSON document read by LoadConfig. Example:
+//
+//	{
+//	  "loggers": {
+//	    "app": {"path": "/var/log/app/app.log", "level": "info", "maxLogSize": 1000000},
+//	    "audit": {"path": "/var/log/app/audit.log", "levels": ["audit"], "flags": ["date", "time", "UTC"]}
+//	  }
+//	}
+type Config struct {
+	Loggers map[string]LoggerConfig `json:"loggers"`
+}
+
+// LoggerConfig holds the parameters to New for one named logger. Omitted fields use
+// the defaults documented on each field.
+type LoggerConfig struct {
+	// Path is the log file path; empty logs to STDOUT.
+	Path string `json:"path"`
+	// Levels defaults to DefaultLevels.
+	Levels []string `json:"levels"`
+	// Level is the name of the logging level, one of Levels; defaults to the lowest level.
+	Level string `json:"level"`
+	// Flags are names from FlagNames; omitted uses DefaultFlags, and [] uses no flags.
+	Flags []string `json:"flags"`
+	// CheckLogSize is unused, and kept for compatibility; see New.
+	CheckLogSize int `json:"checkLogSize"`
+	// MaxLogSize defaults to DefaultMaxLogSize.
+	MaxLogSize int64 `json:"maxLogSize"`
+	// VModule is a Logger.SetVModule spec.
+	VModule string `json:"vmodule"`
+	// TimeFormat is a name from TimeFormatNames, or a time.Format layout; see
+	// Logger.SetTimeFormat. Omitted uses the timestamp from Flags.
+	TimeFormat string `json:"timeFormat"`
+	// TimeZone is "UTC", "Local" or a time zone database name, such as "Europe/Berlin",
+	// for TimeFormat. Omitted uses UTC if Flags include "UTC", otherwise local time.
+	TimeZone string `json:"timeZone"`
+}
+
+const (
+	// DefaultCheckLogSize is the checkLogSize used by LoadConfig when none is configured.
+	// checkLogSize is unused, and kept for compatibility; see New.
+	DefaultCheckLogSize = 10
+	// DefaultMaxLogSize is the maxLogSize used by LoadConfig when none is configured.
+	DefaultMaxLogSize = 10 * 1024 * 1024
+)
+
+var (
+	// FlagNames maps the names accepted in LoggerConfig.Flags to the log package flags.
+	FlagNames = map[string]int{
+		"date":         log.Ldate,
+		"time":         log.Ltime,
+		"microseconds": log.Lmicroseconds,
+		"longfile":     log.Llongfile,
+		"shortfile":    log.Lshortfile,
+		"UTC":          log.LUTC,
+		"msgprefix":    log.Lmsgprefix,
+	}
+)
+
+// LoadConfig reads the JSON Config at path and calls New for every configured logger.
+// The whole document is validated before any logger is created; errors name the logger
+// and field that caused them.
+func LoadConfig(path string) error {
+	return defaultRegistry.LoadConfig(path)
+}
+
+// LoadConfig is LoadConfig for the loggers in r.
+func (r *Registry) LoadConfig(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file, error:%v", err)
+	}
+	cfg, err := ParseConfig(b)
+	if err != nil {
+		return err
+	}
+	return r.ApplyConfig(cfg)
+}
+
+// ParseConfig decodes a JSON Config. Unknown fields are errors, to catch misspellings.
+func ParseConfig(b []byte) (Config, error) {
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing config, error:%v", err)
+	}
+	return cfg, nil
+}
+
+// ApplyConfig validates cfg, applies environment overrides (see EnvPrefix), then calls New
+// for every configured logger that is not in Map. Loggers already in Map are reconfigured
+// in place, so *Logger pointers callers already have remain valid and no entries are
+// dropped. Loggers in Map but not in cfg are left unchanged.
+// An invalid cfg changes no logger. Loggers are then applied one at a time, in name order,
+// so an error found while applying, such as a file that cannot be opened, leaves the
+// loggers before it with the new configuration and the rest with their prior one.
+func ApplyConfig(cfg Config) error {
+	return defaultRegistry.ApplyConfig(cfg)
+}
+
+// ApplyConfig is ApplyConfig for the loggers in r.
+func (r *Registry) ApplyConfig(cfg Config) error {
+	names := make([]string, 0, len(cfg.Loggers))
+	for name := range cfg.Loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resolved := make([]resolvedConfig, 0, len(names))
+	for _, name := range names {
+		rc, err := cfg.Loggers[name].resolve(name)
+		if err != nil {
+			return err
+		}
+		resolved = append(resolved, rc)
+	}
+
+	for _, rc := range resolved {
+		r.mu.RLock()
+		existing := r.loggers[rc.name]
+		r.mu.RUnlock()
+		if existing != nil {
+			if err := existing.reconfigure(rc); err != nil {
+				return fmt.Errorf("config logger:%s, error:%v", rc.name, err)
+			}
+			continue
+		}
+		if err := r.New(rc.name, rc.path, rc.levels, rc.level, rc.flags, rc.checkLogSize, rc.maxLogSize); err != nil {
+			return fmt.Errorf("config logger:%s, error:%v", rc.name, err)
+		}
+		r.mu.RLock()
+		created := r.loggers[rc.name]
+		r.mu.RUnlock()
+		if err := created.SetVModule(rc.vmodule); err != nil {
+			return fmt.Errorf("config logger:%s, field:vmodule, error:%v", rc.name, err)
+		}
+		created.SetTimeFormat(rc.timeLayout, rc.timeLocation)
+	}
+	return nil
+}
+
+// resolvedConfig is a LoggerConfig with defaults applied and names converted to values.
+type resolvedConfig struct {
+	name         string
+	path         string
+	levels       []string
+	level        LoghLevel
+	flags        int
+	checkLogSize int
+	maxLogSize   int64
+	vmodule      string
+	timeLayout   string
+	timeLocation *time.Location
+}
+
+func (lc LoggerConfig) resolve(name string) (resolvedConfig, error) {
+	rc := resolvedConfig{
+		name:         name,
+		path:         lc.Path,
+		levels:       lc.Levels,
+		flags:        DefaultFlags,
+		checkLogSize: lc.CheckLogSize,
+		maxLogSize:   lc.MaxLogSize,
+		vmodule:      lc.VModule,
+		timeLayout:   lc.TimeFormat,
+	}
+	if name == "" {
+		return rc, fmt.Errorf("config logger name is empty")
+	}
+
+	if rc.levels == nil {
+		rc.levels = DefaultLevels
+	}
+	if len(rc.levels) == 0 {
+		return rc, fmt.Errorf("config logger:%s, field:levels, error:no levels", name)
+	}
+
+	if lc.Level != "" {
+		level, ok := levelIndex(rc.levels, lc.Level)
+		if !ok {
+			return rc, fmt.Errorf("config logger:%s, field:level, error:unknown level:%s, levels:%s",
+				name, lc.Level, strings.Join(rc.levels, ","))
+		}
+		rc.level = level
+	}
+
+	if lc.Flags != nil {
+		rc.flags = 0
+		for _, f := range lc.Flags {
+			v, ok := FlagNames[f]
+			if !ok {
+				return rc, fmt.Errorf("config logger:%s, field:flags, error:unknown flag:%s", name, f)
+			}
+			rc.flags |= v
+		}
+	}
+
+	if rc.checkLogSize < 0 {
+		return rc, fmt.Errorf("config logger:%s, field:checkLogSize, error:negative value:%d", name, rc.checkLogSize)
+	}
+	if rc.checkLogSize == 0 {
+		rc.checkLogSize = DefaultCheckLogSize
+	}
+	if rc.maxLogSize < 0 {
+		return rc, fmt.Errorf("config logger:%s, field:maxLogSize, error:negative value:%d", name, rc.maxLogSize)
+	}
+	if rc.maxLogSize == 0 {
+		rc.maxLogSize = DefaultMaxLogSize
+	}
+
+	if layout, ok := TimeFormatNames[lc.TimeFormat]; ok {
+		rc.timeLayout = layout
+	}
+	if lc.TimeZone != "" {
+		if lc.TimeFormat == "" {
+			return rc, fmt.Errorf("config logger:%s, field:timeZone, error:timeZone requires timeFormat", name)
+		}
+		loc, err := time.LoadLocation(lc.TimeZone)
+		if err != nil {
+			return rc, fmt.Errorf("config logger:%s, field:timeZone, error:%v", name, err)
+		}
+		rc.timeLocation = loc
+	}
+
+	if _, err := applyEnv(&rc); err != nil {
+		return rc, err
+	}
+
+	if _, err := parseVModule(rc.levels, rc.vmodule); err != nil {
+		return rc, fmt.Errorf("config logger:%s, field:vmodule, error:%v", name, err)
+	}
+
+	return rc, nil
+}
@@ -0,0 +1,84 @@
+package logh
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadConfig tests that every configured logger is created with its parameters.
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	appLog := filepath.Join(dir, "app.txt")
+	auditLog := filepath.Join(dir, "audit.txt")
+	cfg := fmt.Sprintf(`{
+  "loggers": {
+    "app": {"path": %q, "level": "warning", "flags": [], "maxLogSize": 1000},
+    "audit": {"path": %q, "levels": ["low", "high"], "level": "HIGH", "flags": ["shortfile"]}
+  }
+}`, appLog, auditLog)
+	cfgPath := filepath.Join(dir, "logh.json")
+	if err := ioutil.WriteFile(cfgPath, []byte(cfg), 0644); err != nil {
+		t.Fatalf("error writing config, error: %v", err)
+	}
+	defer ShutdownAll()
+
+	if err := LoadConfig(cfgPath); err != nil {
+		t.Fatalf("error with LoadConfig, error: %v", err)
+	}
+
+	app, audit := Map["app"], Map["audit"]
+	if app == nil || audit == nil {
+		t.Fatalf("loggers not created")
+	}
+	if app.level != Warning || app.flags != 0 || app.maxLogSize != 1000 ||
+		app.checkLogSize != DefaultCheckLogSize || len(app.levels) != len(DefaultLevels) {
+		t.Errorf("app logger incorrect, logger: %+v", app)
+	}
+	if audit.level != 1 || audit.flags != log.Lshortfile || audit.maxLogSize != DefaultMaxLogSize {
+		t.Errorf("audit logger incorrect, logger: %+v", audit)
+	}
+
+	app.Println(Info, "filtered")
+	app.Println(Error, "app entry")
+	app.Shutdown()
+	logString, _ := readTestLog(appLog, 0)
+	if logString != "error: app entry\n" {
+		t.Errorf("incorrect app output, log: %s", logString)
+	}
+}
+
+// TestConfigErrors tests that errors name the logger and field, and that no logger is
+// created from an invalid document.
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		cfg      string
+		contains string
+	}{
+		{`{"loggers": {"a": {"level": "verbose"}}}`, "logger:a, field:level"},
+		{`{"loggers": {"a": {"flags": ["date", "bogus"]}}}`, "logger:a, field:flags"},
+		{`{"loggers": {"a": {"levels": []}}}`, "logger:a, field:levels"},
+		{`{"loggers": {"a": {"maxLogSize": -1}}}`, "logger:a, field:maxLogSize"},
+		{`{"loggers": {"a": {"checkLogSize": -1}}}`, "logger:a, field:checkLogSize"},
+		{`{"loggers": {"a": {"timeZone": "UTC"}}}`, "logger:a, field:timeZone"},
+		{`{"loggers": {"a": {"timeFormat": "RFC3339", "timeZone": "Nowhere/Bogus"}}}`, "logger:a, field:timeZone"},
+		{`{"loggers": {"a": {"path": "x", "unknown": 1}}}`, `unknown field "unknown"`},
+		{`{"loggers": {"ok": {}, "z": {"level": "nope"}}}`, "logger:z, field:level"},
+	}
+
+	for _, v := range tests {
+		cfg, err := ParseConfig([]byte(v.cfg))
+		if err == nil {
+			err = ApplyConfig(cfg)
+		}
+		if err == nil || !strings.Contains(err.Error(), v.contains) {
+			t.Errorf("incorrect error, config: %s, error: %v", v.cfg, err)
+		}
+		if _, ok := Map["ok"]; ok {
+			t.Errorf("logger created from invalid config: %s", v.cfg)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package logh
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// EnvPrefix starts the environment variables read by ApplyEnv and ApplyConfig:
+	// LOGH_<NAME>_LEVEL and LOGH_<NAME>_PATH, where <NAME> is the logger name in upper case
+	// with characters other than letters and digits replaced by _. For example
+	// LOGH_APP_LEVEL=warning sets the level of logger "app", with the level name resolved
+	// against that logger's levels.
+	EnvPrefix = "LOGH_"
+)
+
+// ApplyEnv applies LOGH_<NAME>_LEVEL and LOGH_<NAME>_PATH overrides to every logger in Map,
+// in place. Loggers created by ApplyConfig and WatchConfig have the overrides applied
+// already, and keep them across reloads. Empty variables are ignored.
+func ApplyEnv() error {
+	return defaultRegistry.ApplyEnv()
+}
+
+// ApplyEnv is ApplyEnv for the loggers in r.
+func (r *Registry) ApplyEnv() error {
+	loggers := r.loggersSnapshot()
+	names := make([]string, 0, len(loggers))
+	for name := range loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		l := loggers[name]
+		rc := l.resolvedConfig(name)
+		changed, err := applyEnv(&rc)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+		if err := l.reconfigure(rc); err != nil {
+			return fmt.Errorf("env logger:%s, error:%v", name, err)
+		}
+	}
+	return nil
+}
+
+// EnvName returns the environment variable for a logger name and setting; for example
+// EnvName("db.pool", "LEVEL") returns LOGH_DB_POOL_LEVEL.
+func EnvName(name string, setting string) string {
+	b := []byte(strings.ToUpper(name))
+	for i, c := range b {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return EnvPrefix + string(b) + "_" + setting
+}
+
+// applyEnv applies the environment overrides for rc.name to rc, returning true if any
+// were set.
+func applyEnv(rc *resolvedConfig) (bool, error) {
+	changed := false
+	levelEnv := EnvName(rc.name, "LEVEL")
+	if v := os.Getenv(levelEnv); v != "" {
+		level, ok := levelIndex(rc.levels, v)
+		if !ok {
+			return false, fmt.Errorf("env %s, logger:%s, error:unknown level:%s, levels:%s",
+				levelEnv, rc.name, v, strings.Join(rc.levels, ","))
+		}
+		rc.level = level
+		changed = true
+	}
+	if v := os.Getenv(EnvName(rc.name, "PATH")); v != "" {
+		rc.path = v
+		changed = true
+	}
+	return changed, nil
+}
+
+// resolvedConfig returns the current configuration of l.
+func (l *Logger) resolvedConfig(name string) resolvedConfig {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return resolvedConfig{
+		name:         name,
+		path:         l.filePath,
+		levels:       l.levels,
+		level:        l.level,
+		flags:        l.flags,
+		checkLogSize: l.checkLogSize,
+		maxLogSize:   l.maxLogSize,
+		vmodule:      l.vmoduleSpec,
+		timeLayout:   l.timeLayout,
+		timeLocation: l.timeLocation,
+	}
+}
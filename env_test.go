@@ -0,0 +1,70 @@
+package logh
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestApplyEnv tests that level and path overrides are applied by ApplyEnv and ApplyConfig,
+// with level names resolved against the logger's levels.
+func TestApplyEnv(t *testing.T) {
+	envLog := filepath.Join(t.TempDir(), "env.txt")
+	defer ShutdownAll()
+	if err := New("env.app", "", []string{"trace", "normal", "alarm"}, 0, 0, 10, 10000); err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map["env.app"]
+
+	setEnv(t, "LOGH_ENV_APP_LEVEL", "ALARM")
+	setEnv(t, "LOGH_ENV_APP_PATH", envLog)
+	if err := ApplyEnv(); err != nil {
+		t.Errorf("error with ApplyEnv, error: %v", err)
+	}
+	if Map["env.app"] != lg || !lg.Enabled(2) || lg.Enabled(1) {
+		t.Errorf("level override not applied")
+	}
+	lg.Println(2, "to env path")
+	lg.Shutdown()
+	logString, _ := readTestLog(envLog, 0)
+	if logString != "alarm: to env path\n" {
+		t.Errorf("path override not applied, log: %s", logString)
+	}
+
+	setEnv(t, "LOGH_ENV_APP_LEVEL", "warning")
+	if err := ApplyEnv(); err == nil || !strings.Contains(err.Error(), "LOGH_ENV_APP_LEVEL, logger:env.app") {
+		t.Errorf("unknown level not reported, error: %v", err)
+	}
+
+	setEnv(t, "LOGH_CONFIGURED_LEVEL", "error")
+	cfg, err := ParseConfig([]byte(`{"loggers": {"configured": {"level": "debug"}}}`))
+	if err != nil {
+		t.Errorf("error with ParseConfig, error: %v", err)
+	}
+	if err := ApplyConfig(cfg); err != nil {
+		t.Errorf("error with ApplyConfig, error: %v", err)
+	}
+	if Map["configured"].Enabled(Audit) || !Map["configured"].Enabled(Error) {
+		t.Errorf("level override not applied by ApplyConfig")
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if v := EnvName("db.pool-2", "LEVEL"); v != "LOGH_DB_POOL_2_LEVEL" {
+		t.Errorf("incorrect name: %s", v)
+	}
+}
+
+// setEnv sets an environment variable, restoring it when the test ends.
+func setEnv(t *testing.T, key string, value string) {
+	prior, ok := os.LookupEnv(key)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, prior)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+	os.Setenv(key, value)
+}
@@ -0,0 +1,131 @@
+package logh
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+)
+
+var (
+	// exitHooks are run by Fatalf and Panicf, in registration order.
+	exitHooks      []func()
+	exitHooksMutex sync.Mutex
+
+	// exit is os.Exit, replaced in tests.
+	exit = os.Exit
+)
+
+// RegisterExitHook registers f to be run by Fatalf and Panicf, after all loggers are
+// synced and before the process exits or panics. Hooks run in registration order.
+func RegisterExitHook(f func()) {
+	exitHooksMutex.Lock()
+	defer exitHooksMutex.Unlock()
+	exitHooks = append(exitHooks, f)
+}
+
+// Fatalf writes the message and the stack traces of all goroutines at the highest level of
+// l, regardless of filtering, syncs every logger in Map, runs the exit hooks, then calls
+// os.Exit(1). If l is nil, or a discard Logger, the message and stacks go to STDERR.
+func (l *Logger) Fatalf(format string, v ...interface{}) {
+	l.fatal(fmt.Sprintf(format, v...))
+	exit(1)
+}
+
+// Panicf is Fatalf, but panics with the message rather than exiting, so deferred functions
+// run and the panic can be recovered.
+func (l *Logger) Panicf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	l.fatal(msg)
+	panic(msg)
+}
+
+// Sync writes any pending sampling, rate limit and deduplication summaries and commits
+// the file to stable storage.
+func (l *Logger) Sync() error {
+	if l == nil || l.discard {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sync()
+}
+
+// SyncAll calls Sync for every logger in Map.
+func SyncAll() error {
+	return defaultRegistry.SyncAll()
+}
+
+// SyncAll calls Sync for every logger in r.
+func (r *Registry) SyncAll() error {
+	var errOut error
+	for _, l := range r.loggersSnapshot() {
+		if err := l.Sync(); err != nil {
+			errOut = fmt.Errorf("error: %v, prior errors: %v", err, errOut)
+		}
+	}
+	return errOut
+}
+
+// fatal implements Fatalf and Panicf, up to exiting or panicking.
+func (l *Logger) fatal(msg string) {
+	// Frames above Output are writeHighest, fatal, and Fatalf or Panicf.
+	l.writeHighest(4, msg+"\n"+string(allStacks()))
+	SyncAll()
+
+	exitHooksMutex.Lock()
+	hooks := append([]func(){}, exitHooks...)
+	exitHooksMutex.Unlock()
+	for _, f := range hooks {
+		f()
+	}
+}
+
+// writeHighest writes entry at the highest level of l, regardless of filtering, then syncs
+// l. calldepth is as for log.Output. If l is nil, a discard Logger, or shut down, entry is
+// written to STDERR.
+func (l *Logger) writeHighest(calldepth int, entry string) {
+	if l == nil || l.discard {
+		fmt.Fprintln(os.Stderr, entry)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	level := LoghLevel(len(l.levels) - 1)
+	if level < 0 || l.loggers[level] == nil {
+		fmt.Fprintln(os.Stderr, entry)
+		return
+	}
+	l.observe(calldepth, level, entry, nil)
+	l.replayRecorder(level)
+	l.flushRepeats()
+	l.emit(calldepth, level, entry)
+	l.sync()
+}
+
+// sync implements Sync; l.mu must be held.
+func (l *Logger) sync() error {
+	l.flushSampled()
+	l.flushRateLimited()
+	l.flushRepeats()
+	if l.file == nil {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("syncing log file, error:%v", err)
+	}
+	return nil
+}
+
+// allStacks returns the stack traces of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,68 @@
+package logh
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFatalf tests that the message and stacks are written at the highest level regardless
+// of filtering, and that hooks run before exit.
+func TestFatalf(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, []string{"low", "high", "fatal"}, 1, 0, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	var calls []string
+	defer func() { exitHooks = nil; exit = os.Exit }()
+	RegisterExitHook(func() { calls = append(calls, "hook") })
+	exit = func(code int) { calls = append(calls, fmt.Sprintf("exit %d", code)) }
+	Map[loggerName].SetRateLimit(1, time.Hour)
+	for i := 0; i < 2; i++ {
+		Map[loggerName].Fatalf("cannot continue: %d", 42+i)
+	}
+	if strings.Join(calls, ",") != "hook,exit 1,hook,exit 1" {
+		t.Errorf("incorrect calls: %v", calls)
+	}
+
+	// Fatalf synced the file, so it can be read before Shutdown.
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	// Rate limiting, like all filtering, does not apply.
+	if !strings.HasPrefix(logString, "fatal: cannot continue: 42\ngoroutine ") ||
+		!strings.Contains(logString, "fatal: cannot continue: 43\ngoroutine ") ||
+		!strings.Contains(logString, "logh.TestFatalf") {
+		t.Errorf("incorrect output: %s", logString)
+	}
+	Map[loggerName].Shutdown()
+}
+
+// TestPanicf tests that Panicf panics with the message after writing it.
+func TestPanicf(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Error, 0, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	hooked := false
+	defer func() { exitHooks = nil }()
+	RegisterExitHook(func() { hooked = true })
+
+	func() {
+		defer func() {
+			if r := recover(); r != "bad state: x" || !hooked {
+				t.Errorf("incorrect panic: %v, hooked: %t", r, hooked)
+			}
+		}()
+		Map[loggerName].Panicf("bad state: %s", "x")
+	}()
+	Map[loggerName].Shutdown()
+
+	logString, _ := readTestLog(testLog, 0)
+	if !strings.HasPrefix(logString, "error: bad state: x\ngoroutine ") {
+		t.Errorf("incorrect output: %s", logString)
+	}
+}
@@ -0,0 +1,86 @@
+package logh
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLazy tests that Lazy arguments are only evaluated when the entry is written, and
+// that Enabled reports the level filter.
+func TestLazy(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Warning, 0, 10, 10000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+
+	calls := 0
+	expensive := Lazy(func() string {
+		calls++
+		return "expensive"
+	})
+	Map[loggerName].Printf(Debug, "filtered %s", expensive)
+	Map[loggerName].Println(Info, expensive)
+	if calls != 0 {
+		t.Errorf("Lazy evaluated for filtered entries, calls: %d", calls)
+	}
+	Map[loggerName].Printf(Error, "written %s", expensive)
+	Map[loggerName].Shutdown()
+	if calls != 1 {
+		t.Errorf("Lazy not evaluated once for written entry, calls: %d", calls)
+	}
+	logString, _ := readTestLog(testLog, 0)
+	if logString != "error: written expensive\n" {
+		t.Errorf("incorrect output, log: %s", logString)
+	}
+
+	if Map[loggerName].Enabled(Info) || !Map[loggerName].Enabled(Warning) ||
+		!Map[loggerName].Enabled(Error) || Map[loggerName].Enabled(Error+1) {
+		t.Errorf("Enabled incorrect for level Warning")
+	}
+	var nilLogger *Logger
+	if nilLogger.Enabled(Error) {
+		t.Errorf("Enabled true for nil Logger")
+	}
+	if strings.Contains(logString, "filtered") {
+		t.Errorf("filtered entry written, log: %s", logString)
+	}
+}
+
+// TestLazyLogs tests that a Lazy argument, and a field value, that log to the same Logger
+// do not deadlock.
+func TestLazyLogs(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 10, 10000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	inner := Lazy(func() string {
+		lg.Println(Debug, "inner")
+		return "outer"
+	})
+
+	done := make(chan struct{})
+	go func() {
+		lg.Printf(Info, "%s", inner)
+		lg.Printw(Info, "fields", "value", inner)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("deadlock logging from a Lazy argument")
+	}
+	lg.Shutdown()
+
+	expected := "debug: inner\n" +
+		"info: outer\n" +
+		"debug: inner\n" +
+		"info: fields value=outer\n"
+	logString, _ := readTestLog(testLog, 0)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
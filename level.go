@@ -0,0 +1,81 @@
+package logh
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseLevel returns the level for a name in the levels of l. An exact match is preferred,
+// then a case insensitive match.
+func (l *Logger) ParseLevel(name string) (LoghLevel, error) {
+	if l == nil {
+		return 0, fmt.Errorf("nil Logger")
+	}
+	levels := l.currentLevels()
+	level, ok := levelIndex(levels, name)
+	if !ok {
+		return 0, fmt.Errorf("unknown level:%s, levels:%s", name, strings.Join(levels, ","))
+	}
+	return level, nil
+}
+
+// String returns the name of lvl in DefaultLevels. Levels outside DefaultLevels are
+// returned as LoghLevel(n); use the levels of the Logger for user defined levels.
+func (lvl LoghLevel) String() string {
+	if lvl < 0 || int(lvl) >= len(DefaultLevels) {
+		return "LoghLevel(" + strconv.Itoa(int(lvl)) + ")"
+	}
+	return DefaultLevels[lvl]
+}
+
+// MarshalText implements encoding.TextMarshaler, using the DefaultLevels name. User defined
+// levels above DefaultLevels are written as the level number, as accepted by UnmarshalText.
+func (lvl LoghLevel) MarshalText() ([]byte, error) {
+	if lvl < 0 {
+		return nil, fmt.Errorf("negative level, level:%d", lvl)
+	}
+	if int(lvl) >= len(DefaultLevels) {
+		return []byte(strconv.Itoa(int(lvl))), nil
+	}
+	return []byte(DefaultLevels[lvl]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The text is a DefaultLevels name,
+// matched case insensitively, or a level number, which allows user defined levels.
+func (lvl *LoghLevel) UnmarshalText(text []byte) error {
+	return lvl.Set(string(text))
+}
+
+// Set implements flag.Value, so a LoghLevel can be a command line flag:
+//
+//	level := logh.Info
+//	flag.Var(&level, "level", "logging level")
+func (lvl *LoghLevel) Set(s string) error {
+	if level, ok := levelIndex(DefaultLevels, s); ok {
+		*lvl = level
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return fmt.Errorf("unknown level:%s, levels:%s", s, strings.Join(DefaultLevels, ","))
+	}
+	*lvl = LoghLevel(n)
+	return nil
+}
+
+// levelIndex returns the index of name in levels. An exact match is preferred, then a
+// case insensitive match.
+func levelIndex(levels []string, name string) (LoghLevel, bool) {
+	for i, v := range levels {
+		if v == name {
+			return LoghLevel(i), true
+		}
+	}
+	for i, v := range levels {
+		if strings.EqualFold(v, name) {
+			return LoghLevel(i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,68 @@
+package logh
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	defer ShutdownAll()
+	if err := New("levels", "", []string{"trace", "normal", "alarm"}, 0, 0, 10, 10000); err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	for name, expected := range map[string]LoghLevel{"trace": 0, "NORMAL": 1, "alarm": 2} {
+		lvl, err := Map["levels"].ParseLevel(name)
+		if err != nil || lvl != expected {
+			t.Errorf("ParseLevel incorrect, name: %s, level: %d, error: %v", name, lvl, err)
+		}
+	}
+	if _, err := Map["levels"].ParseLevel("warning"); err == nil {
+		t.Errorf("ParseLevel accepted a level not in the Logger levels")
+	}
+}
+
+// TestLevelText tests String, text marshaling and flag.Value.
+func TestLevelText(t *testing.T) {
+	if Warning.String() != "warning" || LoghLevel(9).String() != "LoghLevel(9)" {
+		t.Errorf("String incorrect: %s, %s", Warning, LoghLevel(9))
+	}
+
+	cfg := struct {
+		Level LoghLevel `json:"level"`
+	}{Audit}
+	b, err := json.Marshal(cfg)
+	if err != nil || string(b) != `{"level":"audit"}` {
+		t.Errorf("MarshalText incorrect, json: %s, error: %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"Error"}`), &cfg); err != nil || cfg.Level != Error {
+		t.Errorf("UnmarshalText incorrect, level: %d, error: %v", cfg.Level, err)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"verbose"}`), &cfg); err == nil {
+		t.Errorf("UnmarshalText accepted unknown level")
+	}
+	if _, err := LoghLevel(-1).MarshalText(); err == nil {
+		t.Errorf("MarshalText accepted a negative level")
+	}
+
+	// User defined levels above DefaultLevels round trip as numbers.
+	cfg.Level = 7
+	b, err = json.Marshal(cfg)
+	if err != nil || string(b) != `{"level":"7"}` {
+		t.Errorf("MarshalText incorrect, json: %s, error: %v", b, err)
+	}
+	cfg.Level = 0
+	if err := json.Unmarshal(b, &cfg); err != nil || cfg.Level != 7 {
+		t.Errorf("round trip incorrect, level: %d, error: %v", cfg.Level, err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	level := Info
+	fs.Var(&level, "level", "logging level")
+	if err := fs.Parse([]string{"-level", "debug"}); err != nil || level != Debug {
+		t.Errorf("flag.Value incorrect, level: %d, error: %v", level, err)
+	}
+	if err := fs.Parse([]string{"-level", "7"}); err != nil || level != 7 {
+		t.Errorf("numeric flag incorrect, level: %d, error: %v", level, err)
+	}
+}
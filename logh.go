@@ -12,14 +12,33 @@ package logh
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 type LoghLevel int
 
+// Field is a key/value pair appended to a log entry as key=value.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Lazy defers building an expensive argument until the entry is actually written. Pass
+// a Lazy as an argument to any print function; it is only called if the entry passes the
+// level filter, as formatting calls its String method. Arguments are formatted without
+// the Logger locked, so a Lazy, or any fmt.Stringer, may itself log, including to the
+// same Logger.
+//
+//	l.Printf(Debug, "state: %s", Lazy(func() string { return dump(state) }))
+type Lazy func() string
+
 // Constants for use with DefaultLevels.
 const (
 	Debug LoghLevel = iota
@@ -30,17 +49,58 @@ const (
 )
 
 type Logger struct {
-	checkLogSize           int
-	flags                  int
-	level                  LoghLevel
-	levels                 []string
-	levelMaxWidth          int
-	loggers                []*log.Logger
-	file                   *os.File
-	filePath               string
-	maxLogSize             int64
-	rotation               int
-	writesSinceCheckRotate int
+	// mu guards all other fields. It is held while writing, rotating and reconfiguring,
+	// so those are serialized per Logger.
+	mu sync.Mutex
+
+	checkLogSize  int
+	flags         int
+	level         LoghLevel
+	levels        []string
+	levelMaxWidth int
+	loggers       []*log.Logger
+	file          *os.File
+	filePath      string
+	maxLogSize    int64
+	rotation      int
+	// size is the number of bytes in file, counted as they are written.
+	size int64
+
+	// output is the Registry output, used when there is no filePath or the file cannot be
+	// opened; file is nil then.
+	output io.Writer
+
+	// vmodule holds the SetVModule overrides, and vmoduleCache the index of the matching
+	// rule (-1 for none) by call site program counter.
+	vmodule      []vmoduleRule
+	vmoduleCache map[uintptr]int
+	vmoduleSpec  string
+
+	// discard is true for the Logger returned by Get for missing names.
+	discard bool
+
+	// sampler is set by SetSampling, rateLimiter by SetRateLimit and deduplicator by
+	// SetDeduplicate.
+	sampler      *sampler
+	rateLimiter  *rateLimiter
+	deduplicator *deduplicator
+
+	// recorder is set by SetFlightRecorder.
+	recorder *recorder
+
+	// callerInfo is set by SetCallerInfo and SetStackTrace, nil when no option is enabled.
+	callerInfo *callerInfo
+
+	// timeLayout and timeLocation are set by SetTimeFormat; an empty timeLayout uses the
+	// timestamp from flags.
+	timeLayout   string
+	timeLocation *time.Location
+
+	// clock is set by SetClock; nil uses time.Now.
+	clock Clock
+
+	// observer is set by SetObserver.
+	observer func(Entry)
 }
 
 const (
@@ -59,9 +119,13 @@ var (
 	// of allowing a main function to configure loggers, and libraries or other functions
 	// can just try to logger to a specific named logger, without concern for log size or if
 	// the named logger even exists.
+	// Indexing Map is not safe while loggers are being created or removed concurrently;
+	// use Get, Names and Remove instead. Map is the map of the default Registry, and
+	// must not be reassigned.
 	Map = map[string]*Logger{}
 
-	defaultOutput = os.Stdout
+	// defaultRegistry is used by the package level functions.
+	defaultRegistry = &Registry{loggers: Map, output: os.Stdout}
 )
 
 // New adds a new logger. This logger supports rotation of 2 files; suffix
@@ -70,20 +134,27 @@ var (
 // 	 filePath - fully qualified file path to which to log.
 // 	 levels - log levels, priority order (low to high). The strings are used for log prefixes.
 // 	 level - index into levels specifying the current log level.
-// 	 checkLogSize, maxLogSize - Every checkLogSize number of calls, the log file size is
-//     checked, and if it exceeds maxLogSize, the file is rotated.
-//     High(er) values for checkLogSize will improve performance due to reduced calls to get the
-//     file size, but will allow the actual file size to overshoot maxLogSize.
-//     Low(er) values of checkLogSize will insure less overshoot on actual log size, but will
-//     incur the penalty of checking file size more frequently.
+// 	 checkLogSize - unused, and kept for compatibility; the bytes written are counted instead.
+// 	 maxLogSize - the file is rotated before an entry that would take it over maxLogSize
+//     bytes, so files do not exceed maxLogSize. An entry larger than maxLogSize is written
+//     to an empty file.
 func New(name string, filePath string, levels []string, level LoghLevel, flags int,
 	checkLogSize int, maxLogSize int64) error {
+	return defaultRegistry.New(name, filePath, levels, level, flags, checkLogSize, maxLogSize)
+}
+
+// New adds a new logger to r; see the package function New.
+func (r *Registry) New(name string, filePath string, levels []string, level LoghLevel, flags int,
+	checkLogSize int, maxLogSize int64) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
 	// Shutdown and delete any existing loggers at this name.
-	if _, ok := Map[name]; ok {
-		Map[name].Shutdown()
+	if _, ok := r.loggers[name]; ok {
+		r.loggers[name].Shutdown()
 	}
-	delete(Map, name)
+	delete(r.loggers, name)
 
 	lg := Logger{
 		checkLogSize: checkLogSize,
@@ -92,6 +163,7 @@ func New(name string, filePath string, levels []string, level LoghLevel, flags i
 		levels:       levels,
 		filePath:     filePath,
 		maxLogSize:   maxLogSize,
+		output:       r.output,
 	}
 	logger := &lg
 
@@ -111,86 +183,231 @@ func New(name string, filePath string, levels []string, level LoghLevel, flags i
 		return err
 	}
 
-	// initialize levelMaxWidth, used to format output so the prefix is constant length
-	// for the various Levels.
-	for _, v := range logger.levels {
-		if len(v) > logger.levelMaxWidth {
-			logger.levelMaxWidth = len(v)
-		}
-	}
+	logger.initializeLevelMaxWidth()
 
-	Map[name] = logger
+	r.loggers[name] = logger
 	return nil
 }
 
+// Enabled returns true if an entry at level would be written. Use this to guard blocks of
+// code that exist only to build log output.
+func (l *Logger) Enabled(level LoghLevel) bool {
+	if l == nil || l.discard {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(level) < len(l.levels) && level >= l.threshold(2)
+}
+
+// OutputDepth logs msg, attributing the entry to the caller skip frames above the caller
+// of OutputDepth. skip 0 is the caller of OutputDepth, which is the same as Print. Wrapper
+// functions pass 1 (or more, for nested wrappers) so the entry reports the real call site
+// rather than the wrapper.
+func (l *Logger) OutputDepth(skip int, level LoghLevel, msg string) {
+	l.printCommon(skip, level, msg, nil, func() string { return msg })
+}
+
+// Print formats using the default formats, as fmt.Sprint, and logs the result.
+func (l *Logger) Print(level LoghLevel, v ...interface{}) {
+	l.printCommon(0, level, printKey(v), nil, sprint(v))
+}
+
 // Printf wraps the log.Printf in order to rotate the file.
 func (l *Logger) Printf(level LoghLevel, format string, v ...interface{}) {
-	l.printCommon(level, format, v...)
+	l.printCommon(0, level, format, nil, sprintf(format, v))
 }
 
-// Println wraps the log.Println in order to rotate the file.
+// Println formats as fmt.Sprintln; operands are always separated by spaces.
 func (l *Logger) Println(level LoghLevel, v ...interface{}) {
-	l.printCommon(level, "%s", v...)
+	l.printCommon(0, level, printKey(v), nil, sprintln(v))
+}
+
+// Printw logs msg followed by structured key=value fields. keysAndValues are alternating
+// string keys and values, and may also contain Field values. A non-string key, or a
+// final key without a value, is logged with the key !BADKEY.
+//
+//	l.Printw(Info, "request done", "status", 200, "bytes", 512)
+func (l *Logger) Printw(level LoghLevel, msg string, keysAndValues ...interface{}) {
+	l.printCommon(0, level, msg, keyValueFields(keysAndValues), func() string { return msg })
 }
 
-// Shutdown shuts down loggers and closes the file.
+// Shutdown shuts down loggers and closes the file. Entries logged after Shutdown are dropped.
 func (l *Logger) Shutdown() error {
-	for i := range l.loggers {
-		l.loggers[i] = nil
-	}
-	if l.file != nil {
-		if err := l.file.Close(); err != nil {
-			return fmt.Errorf("closing log file, error:%v", err)
-		}
-	}
-	return nil
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushSampled()
+	l.flushRateLimited()
+	l.flushRepeats()
+	return l.shutdown()
 }
 
 // ShutdownAll is a convenience function to shutdown all running loggers and clear the Map.
 func ShutdownAll() error {
+	return defaultRegistry.ShutdownAll()
+}
+
+// ShutdownAll shuts down all loggers in r and removes them.
+func (r *Registry) ShutdownAll() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	var errOut error
-	for k := range Map {
-		err := Map[k].Shutdown()
+	for k := range r.loggers {
+		err := r.loggers[k].Shutdown()
 		if err != nil {
 			errOut = fmt.Errorf("error: %v, prior errors: %v", err, errOut)
 		}
+		// Delete in place, rather than assigning a new map, as Map is the default
+		// Registry's map.
+		delete(r.loggers, k)
 	}
-	Map = map[string]*Logger{}
 	return errOut
 }
 
-func (l *Logger) checkSizeAndRotate() error {
-	if l.filePath == "" {
-		return nil
+func (l *Logger) shutdown() error {
+	for i := range l.loggers {
+		l.loggers[i] = nil
 	}
+	// The output is shared by all loggers without a file, and is not closed.
+	if l.file != nil {
+		err := l.file.Close()
+		l.file = nil
+		if err != nil {
+			return fmt.Errorf("closing log file, error:%v", err)
+		}
+	}
+	return nil
+}
 
-	l.writesSinceCheckRotate = 0
-	var err error
-	var fi os.FileInfo
-	if fi, err = os.Stat(l.filePath + "." + strconv.Itoa(l.rotation)); err != nil {
-		return err
+// rotate opens the next rotation, removing its prior contents, then closes the current
+// file. If the next rotation cannot be opened, the current file stays open and rotation
+// and size are unchanged. l.mu must be held.
+func (l *Logger) rotate() error {
+	rotation := l.rotation + 1
+	if rotation >= maxRotations {
+		rotation = 0
+	}
+	fp := l.filePath + "." + strconv.Itoa(rotation)
+	if err := os.Remove(fp); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotating log file, error:%v", err)
+	}
+	file, err := os.OpenFile(fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("rotating log file, error:%v", err)
+	}
+	err = l.file.Close()
+	// The loggers write through sizeWriter, so they write to the new file.
+	l.file, l.rotation, l.size = file, rotation, 0
+	if err != nil {
+		return fmt.Errorf("closing log file, error:%v", err)
 	}
+	return nil
+}
+
+// sizeWriter writes to the file of a Logger, counting the bytes written, and rotating
+// before a write that would take the file over maxLogSize. The log package makes one
+// write per entry, so entries are not split across files. l.mu must be held.
+type sizeWriter struct {
+	l *Logger
+}
 
-	if fi.Size() > l.maxLogSize {
-		l.rotation++
-		if l.rotation >= maxRotations {
-			l.rotation = 0
+func (w sizeWriter) Write(p []byte) (int, error) {
+	l := w.l
+	if l.size > 0 && l.size+int64(len(p)) > l.maxLogSize {
+		// The entry is written to the current file if rotation fails, and rotation is
+		// tried again on the next write.
+		if err := l.rotate(); err != nil {
+			fmt.Fprintf(l.output, "%v\n", err)
 		}
-		if err := os.Remove(l.filePath + "." + strconv.Itoa(l.rotation)); err != nil && !os.IsNotExist(err) {
-			return err
+	}
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// String calls f, implementing fmt.Stringer.
+func (f Lazy) String() string {
+	return f()
+}
+
+// formatFields renders fields as space separated key=value pairs, with a leading space.
+// Values containing spaces or quotes are quoted.
+func formatFields(fields []Field) string {
+	var sb strings.Builder
+	for _, f := range fields {
+		v := fmt.Sprintf("%v", f.Value)
+		if strings.ContainsAny(v, " \t\n\"=") {
+			v = strconv.Quote(v)
 		}
-		if err := l.openFileAndInitialize(); err != nil {
-			return err
+		sb.WriteString(" " + f.Key + "=" + v)
+	}
+	return sb.String()
+}
+
+// keyValueFields converts alternating keys and values to Fields.
+func keyValueFields(keysAndValues []interface{}) []Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i++ {
+		switch k := keysAndValues[i].(type) {
+		case Field:
+			fields = append(fields, k)
+		case string:
+			if i+1 >= len(keysAndValues) {
+				fields = append(fields, Field{"!BADKEY", k})
+				break
+			}
+			fields = append(fields, Field{k, keysAndValues[i+1]})
+			i++
+		default:
+			fields = append(fields, Field{"!BADKEY", k})
 		}
 	}
+	return fields
+}
 
-	return nil
+// initializeLevelMaxWidth initializes levelMaxWidth, used to format output so the prefix
+// is constant length for the various Levels.
+func (l *Logger) initializeLevelMaxWidth() {
+	l.levelMaxWidth = 0
+	for _, v := range l.levels {
+		if len(v) > l.levelMaxWidth {
+			l.levelMaxWidth = len(v)
+		}
+	}
 }
 
 func (l *Logger) initializeLoggers() {
+	w := l.writer()
 	l.loggers = make([]*log.Logger, len(l.levels))
 	for i, v := range l.levels {
-		l.loggers[i] = log.New(l.file, v+": ", l.flags)
+		l.loggers[i] = log.New(w, v+": ", l.logFlags())
+	}
+}
+
+// writer returns the file, or the output when there is no file.
+func (l *Logger) writer() io.Writer {
+	if l.file != nil {
+		return sizeWriter{l}
+	}
+	return l.output
+}
+
+// SetOutput sets the writer used when l has no file, in place of the Registry output. A
+// nil w restores STDOUT.
+func (l *Logger) SetOutput(w io.Writer) {
+	if w == nil {
+		w = os.Stdout
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.output = w
+	// A shut down Logger stays shut down.
+	if l.file == nil && len(l.loggers) > 0 && l.loggers[0] != nil {
+		l.initializeLoggers()
 	}
 }
 
@@ -202,39 +419,41 @@ func (l *Logger) initializeRotation() error {
 		if err != nil {
 			// File does not exist; should be os.IsNotExist(err)
 			l.rotation = i
+			l.size = 0
 			return nil
 		}
 		if fi.Size() < l.maxLogSize {
-			// Add to existing file.
+			// Add to existing file; its size seeds the count of bytes written.
 			l.rotation = i
+			l.size = fi.Size()
 			return nil
 		}
 	}
 
 	// All files are >= maxLogSize, clear and use rotation 0
 	l.rotation = 0
+	l.size = 0
 	return os.Remove(l.filePath + ".0")
 }
 
 // openFileAndInitialize opens the file and assigns loggers. On error, which can happen
-// at startup or during file rotations, errors will result in the defaultOutput being
+// at startup or during file rotations, errors will result in the output being
 // used for logging.
 func (l *Logger) openFileAndInitialize() error {
 	var err, errors error
-	l.writesSinceCheckRotate = 0
 	if l.filePath == "" {
-		l.file = defaultOutput
+		l.file = nil
 	} else {
 		if l.file != nil {
 			// When calling due to rotation, Shutdown running logger.
-			if err := l.Shutdown(); err != nil {
+			if err := l.shutdown(); err != nil {
 				errors = fmt.Errorf("closing log file, error:%v", err)
 			}
 		}
 		fp := l.filePath + "." + strconv.Itoa(l.rotation)
 		l.file, err = os.OpenFile(fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 		if err != nil {
-			l.file = defaultOutput
+			l.file = nil
 			errors = fmt.Errorf("%v, opening log file, error:%v", errors, err)
 		}
 	}
@@ -248,25 +467,81 @@ func (l *Logger) openFileAndInitialize() error {
 // and Println. (This could have been in Printf, and Println call Printf. But then
 // the call stack is different, and the argument to Output would need to change
 // depending on the caller.)
-func (l *Logger) printCommon(level LoghLevel, format string, v ...interface{}) {
-	if l == nil {
+// msg is only called if the entry is written, so filtered entries are not formatted.
+// skip is the number of additional frames between the exported print function and the
+// call site to report. key identifies the message before formatting, for sampling.
+func (l *Logger) printCommon(skip int, level LoghLevel, key string, fields []Field, msg func() string) {
+	if l == nil || l.discard {
+		return
+	}
+	if !l.decide(skip, level, key) {
 		return
 	}
 
-	if int(level) >= len(l.levels) {
-		fmt.Printf("input level was outside range, level:%d, len(levels)-1:%d", level, len(l.levels)-1)
+	// Formatting calls String on the arguments, such as a Lazy, which may log to l, so l.mu
+	// is not held.
+	m := msg()
+	f := formatFields(fields)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// l may have been reconfigured or shut down while formatting.
+	if int(level) >= len(l.loggers) || l.loggers[level] == nil {
 		return
 	}
+	if level >= l.threshold(3+skip) {
+		l.observe(3+skip, level, m, fields)
+		l.replayRecorder(level)
+		l.write(3+skip, level, l.entry(3+skip, level, m+f))
+	} else if l.recorder != nil {
+		l.record(3+skip, level, l.entry(3+skip, level, m+f))
+	}
+}
 
-	if level >= l.level {
-		l.loggers[level].Output(3, fmt.Sprintf(format, v...))
+// decide returns true if an entry at level from the call site skip frames above the
+// caller of printCommon is to be written or flight recorded. Sampling and rate limiting
+// count the entry here, before it is formatted.
+func (l *Logger) decide(skip int, level LoghLevel, key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if level < 0 || int(level) >= len(l.levels) {
+		fmt.Fprintf(l.writer(), "input level was outside range, level:%d, len(levels)-1:%d\n", level, len(l.levels)-1)
+		return false
+	}
+	// A shut down Logger drops entries.
+	if l.loggers[level] == nil {
+		return false
 	}
 
-	if l.filePath == "" {
-		return
+	// The frames above threshold and allow are decide, printCommon and the print function.
+	if level >= l.threshold(4+skip) {
+		return l.sample(skip+1, level, key) && l.allow(4+skip, level)
 	}
-	l.writesSinceCheckRotate++
-	if l.writesSinceCheckRotate >= l.checkLogSize {
-		l.checkSizeAndRotate()
+	return l.recorder != nil
+}
+
+// printKey returns the sampling key for Print and Println: the first operand, if it is a
+// string.
+func printKey(v []interface{}) string {
+	if len(v) > 0 {
+		if s, ok := v[0].(string); ok {
+			return s
+		}
 	}
+	return ""
+}
+
+func sprint(v []interface{}) func() string {
+	return func() string { return fmt.Sprint(v...) }
+}
+
+func sprintf(format string, v []interface{}) func() string {
+	return func() string { return fmt.Sprintf(format, v...) }
+}
+
+// sprintln drops the trailing newline from fmt.Sprintln, as fields may follow the message
+// and Output adds the newline.
+func sprintln(v []interface{}) func() string {
+	return func() string { return strings.TrimSuffix(fmt.Sprintln(v...), "\n") }
 }
@@ -1,11 +1,13 @@
 package logh
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -37,21 +39,20 @@ func (tp *testPrint) Println(t *testing.T) {
 	Map[loggerName].Println(tp.level, tp.msg)
 }
 
-// TestDefaultOutput tests that non-file logging does go to the defaultLogger
+// TestDefaultOutput tests that non-file logging does go to the Registry output
 func TestDefaultOutput(t *testing.T) {
 	rf, wf, err := os.Pipe()
 	if err != nil {
 		t.Errorf("Cannot make pipe, error: %v", err)
 	}
 
-	defaultOutput = wf
-	testSetup(t)
-	err = New(loggerName, "", DefaultLevels, Debug, 0, 10, 1000)
+	r := NewRegistry(wf)
+	err = r.New(loggerName, "", DefaultLevels, Debug, 0, 10, 1000)
 	if err != nil {
 		t.Errorf("error with New, error: %v", err)
 	}
 
-	Map[loggerName].Println(0, "Sending data to defaultOutput")
+	r.Get(loggerName).Println(0, "Sending data to defaultOutput")
 	buf := make([]byte, 1000)
 	n, err := rf.Read(buf)
 	if err != nil {
@@ -63,12 +64,11 @@ func TestDefaultOutput(t *testing.T) {
 		t.Errorf("Incorrect output for defaultOutput, received: %s", out)
 	}
 
-	Map[loggerName].Shutdown()
+	r.ShutdownAll()
 }
 
 // TestLineNumbers is used to verify the Output calldepth parameter is the correct
-// value. That does mean that changes to this file required updating the test
-// for new line numbers.
+// value.
 func TestLineNumbers(t *testing.T) {
 	testSetup(t)
 	err := New(loggerName, testLog, DefaultLevels, Debug, log.Lshortfile, 10, 1000)
@@ -76,20 +76,34 @@ func TestLineNumbers(t *testing.T) {
 		t.Errorf("error with New, error: %v", err)
 	}
 
+	_, _, line, _ := runtime.Caller(0)
 	Map[loggerName].Printf(0, "this is the Printf call")
 	Map[loggerName].Println(0, "this is the Println call")
+	Map[loggerName].Print(0, "this is the Print call")
+	Map[loggerName].Printw(0, "this is the Printw call")
+	Map[loggerName].OutputDepth(0, 0, "this is the OutputDepth call")
+	testWrapper("this is the wrapper call")
 	// Shutdown to flush output.
 	Map[loggerName].Shutdown()
 	logString, _ := readTestLog(testLog, 0)
 	fmt.Println(logString)
-	if !strings.Contains(logString, "logh_test.go:79: this is the Printf call") ||
-		!strings.Contains(logString, "logh_test.go:80: this is the Println call") {
-		t.Errorf("Output calldepth problem")
+	for i, v := range []string{"Printf", "Println", "Print", "Printw", "OutputDepth", "wrapper"} {
+		expected := fmt.Sprintf("logh_test.go:%d: this is the %s call", line+1+i, v)
+		if !strings.Contains(logString, expected) {
+			t.Errorf("Output calldepth problem, missing: %s", expected)
+		}
 	}
 }
+
+// testWrapper is a wrapper as a library would write, reporting its caller's line.
+func testWrapper(msg string) {
+	Map[loggerName].OutputDepth(1, Debug, msg)
+}
+
 func TestRotate(t *testing.T) {
 	testSetup(t)
-	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 1, 70)
+	// Each entry is 60 bytes, so 2 entries fill a file exactly.
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 1, 120)
 	if err != nil {
 		t.Errorf("error with New, error: %v", err)
 	}
@@ -140,30 +154,29 @@ func TestRotate(t *testing.T) {
 	shouldContainCheck(t, log0String, log1String, log0ShouldContain, log1ShouldContain)
 
 	subTest++
-	fmt.Printf("\n\nsubtest: %d, Fill log .1; log .0 will now be opened/cleared as it was rotated in.\n", subTest)
+	fmt.Printf("\n\nsubtest: %d, Fill log .1; log .0 is unchanged until the next entry.\n", subTest)
 	out = fmt.Sprintf("%d-12345678901234567890123456789012345678901234567890", subTest)
 	Map[loggerName].Println(0, out)
 	log0String, _ = readTestLog(testLog, 0)
 	fmt.Printf("log0\n%s\n", log0String)
 	log1String, _ = readTestLog(testLog, 1)
 	fmt.Printf("log1\n%s\n", log1String)
-	log0ShouldContain = []int{}
 	log1ShouldContain = append(log1ShouldContain, subTest)
-	if len(log0String) > 0 || len(log1String) == 0 {
+	if len(log0String) != 120 || len(log1String) != 120 {
 		t.Errorf("rotate test %d failed", subTest)
 	}
 	shouldContainCheck(t, log0String, log1String, log0ShouldContain, log1ShouldContain)
 
 	subTest++
-	fmt.Printf("\n\nsubtest: %d, Partially write log .0.\n", subTest)
+	fmt.Printf("\n\nsubtest: %d, Partially write log .0; log .0 is cleared as it is rotated in.\n", subTest)
 	out = fmt.Sprintf("%d-12345678901234567890123456789012345678901234567890", subTest)
 	Map[loggerName].Println(0, out)
 	log0String, _ = readTestLog(testLog, 0)
 	fmt.Printf("log0\n%s\n", log0String)
 	log1String, _ = readTestLog(testLog, 1)
 	fmt.Printf("log1\n%s\n", log1String)
-	log0ShouldContain = append(log0ShouldContain, subTest)
-	if len(log0String) == 0 || len(log1String) == 0 {
+	log0ShouldContain = []int{subTest}
+	if len(log0String) != 60 || len(log1String) != 120 {
 		t.Errorf("rotate test %d failed", subTest)
 	}
 	shouldContainCheck(t, log0String, log1String, log0ShouldContain, log1ShouldContain)
@@ -171,6 +184,78 @@ func TestRotate(t *testing.T) {
 	Map[loggerName].Shutdown()
 }
 
+// TestRotateSize tests that the byte count is seeded from an existing file, and that an
+// entry larger than maxLogSize is written alone to an empty file.
+func TestRotateSize(t *testing.T) {
+	testSetup(t)
+	out := "1234567890123456789012345678901234567890123456789012"
+	for i := 0; i < 2; i++ {
+		err := New(loggerName, testLog, DefaultLevels, Debug, 0, 1, 120)
+		if err != nil {
+			t.Errorf("error with New, error: %v", err)
+		}
+		Map[loggerName].Println(Debug, out)
+		Map[loggerName].Shutdown()
+	}
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 1, 120)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	Map[loggerName].Println(Debug, out)
+	log0String, _ := readTestLog(testLog, 0)
+	log1String, _ := readTestLog(testLog, 1)
+	if len(log0String) != 120 || len(log1String) != 60 {
+		t.Errorf("incorrect sizes, log0: %d, log1: %d", len(log0String), len(log1String))
+	}
+
+	Map[loggerName].Println(Debug, strings.Repeat(out, 3))
+	Map[loggerName].Println(Debug, out)
+	Map[loggerName].Shutdown()
+	log0String, _ = readTestLog(testLog, 0)
+	log1String, _ = readTestLog(testLog, 1)
+	if len(log0String) != 164 || len(log1String) != 60 {
+		t.Errorf("incorrect sizes, log0: %d, log1: %d", len(log0String), len(log1String))
+	}
+}
+
+// TestRotateError tests that an entry is written to the current file when the next
+// rotation cannot be opened, that the error is written to the output, and that rotation
+// is tried again on the next entry.
+func TestRotateError(t *testing.T) {
+	testSetup(t)
+	// A non-empty directory in place of rotation 1 cannot be removed.
+	dir := testLog + ".1"
+	if err := os.MkdirAll(filepath.Join(dir, "file"), 0755); err != nil {
+		t.Fatalf("error with MkdirAll, error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	var buf bytes.Buffer
+	r := NewRegistry(&buf)
+	err := r.New(loggerName, testLog, DefaultLevels, Debug, 0, 1, 100)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	out := "1234567890123456789012345678901234567890123456789012"
+	r.Get(loggerName).Println(Debug, out)
+	r.Get(loggerName).Println(Debug, out)
+	if !strings.HasPrefix(buf.String(), "rotating log file, error:") {
+		t.Errorf("rotation error not written, output: %q", buf.String())
+	}
+	log0String, _ := readTestLog(testLog, 0)
+	if len(log0String) != 120 {
+		t.Errorf("incorrect size, log0: %d", len(log0String))
+	}
+
+	os.RemoveAll(dir)
+	r.Get(loggerName).Println(Debug, out)
+	r.Get(loggerName).Shutdown()
+	log0String, _ = readTestLog(testLog, 0)
+	log1String, _ := readTestLog(testLog, 1)
+	if len(log0String) != 120 || len(log1String) != 60 {
+		t.Errorf("incorrect sizes, log0: %d, log1: %d", len(log0String), len(log1String))
+	}
+}
+
 // TestShowOutput can be used with the -v parameter to just demo the output. This is not an
 // Example because the logger output is not seen by the tests on STDOUT.
 func TestShowOutput(t *testing.T) {
@@ -179,19 +264,19 @@ func TestShowOutput(t *testing.T) {
 		t.Errorf("Cannot make pipe, error: %v", err)
 	}
 
-	defaultOutput = wf
-	testSetup(t)
+	// A Registry with its own output, rather than the default of STDOUT.
+	r := NewRegistry(wf)
 
 	aLog := "app"
-	checkLogSize := 10 // every 10 entries, check log size and rotate if size exceeds maxLogSize.
+	checkLogSize := 10 // unused; files rotate before an entry would exceed maxLogSize.
 	maxLogSize := int64(10000)
-	err = New(aLog, "", DefaultLevels, Debug, DefaultFlags, checkLogSize, maxLogSize)
+	err = r.New(aLog, "", DefaultLevels, Debug, DefaultFlags, checkLogSize, maxLogSize)
 	if err != nil {
 		t.Errorf("error with New, error: %v", err)
 	}
 
 	// Define an alias to use to keep print statements short.
-	lp := Map[aLog].Println
+	lp := r.Get(aLog).Println
 	lp(Debug, "This is a debug level print; debug level logging.")
 	lp(Info, "This is a info level print; debug level logging.")
 	lp(Warning, "This is a warning level print; debug level logging.")
@@ -199,10 +284,10 @@ func TestShowOutput(t *testing.T) {
 	lp(Error, "This is a error level print; debug level logging.")
 
 	// Change to warning level logging
-	err = New(aLog, "", DefaultLevels, Warning, DefaultFlags, checkLogSize, maxLogSize)
-	defer ShutdownAll()
+	err = r.New(aLog, "", DefaultLevels, Warning, DefaultFlags, checkLogSize, maxLogSize)
+	defer r.ShutdownAll()
 	// Re-define alias with New log.
-	lp = Map[aLog].Println
+	lp = r.Get(aLog).Println
 	if err != nil {
 		t.Errorf("error with New, error: %v", err)
 	}
@@ -210,7 +295,7 @@ func TestShowOutput(t *testing.T) {
 	lp(Warning, "Warning and higher do print")
 
 	// Change back to debug level  logging
-	err = New(aLog, "", DefaultLevels, Debug, DefaultFlags, checkLogSize, maxLogSize)
+	err = r.New(aLog, "", DefaultLevels, Debug, DefaultFlags, checkLogSize, maxLogSize)
 	if err != nil {
 		t.Errorf("error with New, error: %v", err)
 	}
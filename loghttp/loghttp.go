@@ -0,0 +1,128 @@
+// Package loghttp provides net/http helpers that log to named logh Loggers.
+package loghttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/paulfdunn/logh"
+)
+
+// responseRecorder wraps a http.ResponseWriter to capture the status and byte count.
+type responseRecorder struct {
+	http.ResponseWriter
+	bytes  int
+	status int
+}
+
+// Middleware returns a http.Handler that calls next, then writes one entry per request to
+// the logger logh.Get(name): method, path, status, bytes, duration and remote address.
+// The http.ResponseWriter passed to next implements http.Flusher and http.Hijacker only
+// when the one being wrapped does.
+// Entries are written at level; responses with a 5xx status are written at logh.Error, or
+// the highest level of loggers with fewer levels, if that is above level.
+// The logger is looked up on each request, so it need not exist when Middleware is
+// called, and nothing is logged while it does not exist. When the request has a W3C
+// traceparent header, trace_id and span_id fields are added.
+func Middleware(name string, level logh.LoghLevel, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rr := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rr.wrap(), r)
+		duration := time.Since(start)
+
+		status := rr.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		lg := logh.Get(name)
+		lvl := level
+		if status >= 500 {
+			errorLevel := logh.Error
+			if highest := lg.HighestLevel(); highest < errorLevel {
+				errorLevel = highest
+			}
+			if errorLevel > lvl {
+				lvl = errorLevel
+			}
+		}
+
+		ctx := r.Context()
+		if tp := r.Header.Get("traceparent"); tp != "" {
+			// An invalid traceparent is ignored, and ctx returned unchanged.
+			ctx, _ = logh.ContextWithTraceparent(ctx, tp)
+		}
+		lg.PrintfContext(ctx, lvl, "method=%s path=%s status=%d bytes=%d duration=%s remote=%s",
+			r.Method, r.URL.Path, status, rr.bytes, duration, r.RemoteAddr)
+	})
+}
+
+// flusher implements http.Flusher for a responseRecorder wrapping a http.Flusher.
+type flusher struct {
+	rr *responseRecorder
+}
+
+// hijacker implements http.Hijacker for a responseRecorder wrapping a http.Hijacker.
+type hijacker struct {
+	rr *responseRecorder
+}
+
+// wrap returns rr, implementing http.Flusher and http.Hijacker when the wrapped
+// ResponseWriter does, so handlers checking for them see the same as without Middleware.
+func (rr *responseRecorder) wrap() http.ResponseWriter {
+	_, canFlush := rr.ResponseWriter.(http.Flusher)
+	_, canHijack := rr.ResponseWriter.(http.Hijacker)
+	switch {
+	case canFlush && canHijack:
+		return struct {
+			*responseRecorder
+			http.Flusher
+			http.Hijacker
+		}{rr, flusher{rr}, hijacker{rr}}
+	case canFlush:
+		return struct {
+			*responseRecorder
+			http.Flusher
+		}{rr, flusher{rr}}
+	case canHijack:
+		return struct {
+			*responseRecorder
+			http.Hijacker
+		}{rr, hijacker{rr}}
+	}
+	return rr
+}
+
+func (f flusher) Flush() {
+	if f.rr.status == 0 {
+		f.rr.status = http.StatusOK
+	}
+	f.rr.ResponseWriter.(http.Flusher).Flush()
+}
+
+// Hijack lets connections be upgraded, for example to WebSockets. A hijacked connection
+// is logged with status 101, Switching Protocols, unless a status was written first.
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h.rr.status == 0 {
+		h.rr.status = http.StatusSwitchingProtocols
+	}
+	return h.rr.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += n
+	return n, err
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
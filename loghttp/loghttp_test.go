@@ -0,0 +1,170 @@
+package loghttp
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/paulfdunn/logh"
+)
+
+// TestMiddleware tests that one entry is written per request, at the configured level,
+// and that 5xx responses are escalated to Error.
+func TestMiddleware(t *testing.T) {
+	testLog := filepath.Join(t.TempDir(), "access.txt")
+	err := logh.New("access", testLog, logh.DefaultLevels, logh.Info, 0, 10, 10000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	defer logh.ShutdownAll()
+
+	h := Middleware("access", logh.Info, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "failed", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "hello")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/fail", nil))
+	logh.Map["access"].Shutdown()
+
+	b, err := ioutil.ReadFile(testLog + ".0")
+	if err != nil {
+		t.Errorf("error reading log, error: %v", err)
+	}
+	logString := string(b)
+	fmt.Println(logString)
+	lines := strings.Split(strings.TrimSpace(logString), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("wrong number of lines, lines: %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "info: method=GET path=/ok status=200 bytes=5 duration=") ||
+		!strings.Contains(lines[0], "remote=192.0.2.1:1234 trace_id=4bf92f3577b34da6a3ce929d0e0e4736") {
+		t.Errorf("incorrect entry, line: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "error: method=POST path=/fail status=500 bytes=7 ") {
+		t.Errorf("5xx not escalated, line: %s", lines[1])
+	}
+}
+
+// TestMiddlewareNoLogger tests that requests are served when the named logger does not exist.
+func TestMiddlewareNoLogger(t *testing.T) {
+	h := Middleware("missing", logh.Info, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("wrong status, status: %d", w.Code)
+	}
+}
+
+// TestMiddlewareFewLevels tests that 5xx responses are escalated to the highest level of a
+// logger with fewer levels than logh.Error.
+func TestMiddlewareFewLevels(t *testing.T) {
+	testLog := filepath.Join(t.TempDir(), "access.txt")
+	err := logh.New("access", testLog, []string{"low", "high"}, 0, 0, 10, 10000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	defer logh.ShutdownAll()
+
+	h := Middleware("access", 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "failed", http.StatusBadGateway)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	logh.Map["access"].Shutdown()
+
+	b, err := ioutil.ReadFile(testLog + ".0")
+	if err != nil {
+		t.Errorf("error reading log, error: %v", err)
+	}
+	if !strings.HasPrefix(string(b), "high: method=GET path=/fail status=502 ") {
+		t.Errorf("5xx not escalated to the highest level, log: %s", b)
+	}
+}
+
+// hijackRecorder is a httptest.ResponseRecorder that implements http.Hijacker.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.conn, bufio.NewReadWriter(bufio.NewReader(h.conn), bufio.NewWriter(h.conn)), nil
+}
+
+// TestMiddlewareHijack tests that the wrapped http.Hijacker is available to handlers, and
+// that a hijacked connection is logged as Switching Protocols.
+func TestMiddlewareHijack(t *testing.T) {
+	testLog := filepath.Join(t.TempDir(), "access.txt")
+	err := logh.New("access", testLog, logh.DefaultLevels, logh.Info, 0, 10, 10000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	defer logh.ShutdownAll()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	h := Middleware("access", logh.Info, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Errorf("ResponseWriter does not implement http.Hijacker")
+			return
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			t.Errorf("error with Hijack, error: %v", err)
+			return
+		}
+		conn.Close()
+	}))
+	h.ServeHTTP(&hijackRecorder{httptest.NewRecorder(), server}, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	logh.Map["access"].Shutdown()
+
+	b, err := ioutil.ReadFile(testLog + ".0")
+	if err != nil {
+		t.Errorf("error reading log, error: %v", err)
+	}
+	if !strings.HasPrefix(string(b), "info: method=GET path=/ws status=101 ") {
+		t.Errorf("incorrect entry, log: %s", b)
+	}
+
+}
+
+// TestMiddlewareInterfaces tests that handlers see http.Flusher and http.Hijacker only
+// when the wrapped ResponseWriter implements them.
+func TestMiddlewareInterfaces(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	for _, v := range []struct {
+		w         http.ResponseWriter
+		canFlush  bool
+		canHijack bool
+	}{
+		{struct{ http.ResponseWriter }{httptest.NewRecorder()}, false, false},
+		{httptest.NewRecorder(), true, false},
+		{&hijackRecorder{httptest.NewRecorder(), server}, true, true},
+	} {
+		h := Middleware("access", logh.Info, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := w.(http.Flusher); ok != v.canFlush {
+				t.Errorf("incorrect http.Flusher, %T: %t", v.w, ok)
+			}
+			if _, ok := w.(http.Hijacker); ok != v.canHijack {
+				t.Errorf("incorrect http.Hijacker, %T: %t", v.w, ok)
+			}
+		}))
+		h.ServeHTTP(v.w, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+}
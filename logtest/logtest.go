@@ -0,0 +1,123 @@
+// Package logtest provides helpers to assert on logh output in unit tests.
+package logtest
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/paulfdunn/logh"
+)
+
+// Name is the name of the Logger created by New, in its own logh.Registry.
+const Name = "logtest"
+
+// ObservedLogs is a concurrency safe record of observed entries.
+type ObservedLogs struct {
+	mu      sync.Mutex
+	entries []logh.Entry
+}
+
+// New returns a Logger with logh.DefaultLevels, logging at level, that records its entries
+// in the returned ObservedLogs rather than writing them. The Logger is in its own
+// logh.Registry, so tests using New can run in parallel, and is shut down when tb ends.
+func New(tb testing.TB, level logh.LoghLevel) (*logh.Logger, *ObservedLogs) {
+	tb.Helper()
+	r := logh.NewRegistry(ioutil.Discard)
+	err := r.New(Name, "", logh.DefaultLevels, level, 0, logh.DefaultCheckLogSize, logh.DefaultMaxLogSize)
+	if err != nil {
+		tb.Fatalf("error with New, error: %v", err)
+	}
+	tb.Cleanup(func() { r.ShutdownAll() })
+	l := r.Get(Name)
+	return l, Observe(tb, l)
+}
+
+// Observe records the entries written by l, such as logh.Get(name), in the returned
+// ObservedLogs until tb ends, then restores the prior observer of l. Entries are still
+// written to the output of l.
+func Observe(tb testing.TB, l *logh.Logger) *ObservedLogs {
+	o := &ObservedLogs{}
+	prior := l.SetObserver(o.add)
+	tb.Cleanup(func() { l.SetObserver(prior) })
+	return o
+}
+
+func (o *ObservedLogs) add(e logh.Entry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = append(o.entries, e)
+}
+
+// Len returns the number of entries recorded.
+func (o *ObservedLogs) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// All returns a copy of the entries recorded, oldest first.
+func (o *ObservedLogs) All() []logh.Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]logh.Entry(nil), o.entries...)
+}
+
+// TakeAll returns the entries recorded, oldest first, and clears them.
+func (o *ObservedLogs) TakeAll() []logh.Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// Messages returns the message of each entry recorded, oldest first.
+func (o *ObservedLogs) Messages() []string {
+	entries := o.All()
+	messages := make([]string, len(entries))
+	for i, e := range entries {
+		messages[i] = e.Message
+	}
+	return messages
+}
+
+// Filter returns a new ObservedLogs holding the entries for which keep returns true.
+func (o *ObservedLogs) Filter(keep func(logh.Entry) bool) *ObservedLogs {
+	filtered := &ObservedLogs{}
+	for _, e := range o.All() {
+		if keep(e) {
+			filtered.entries = append(filtered.entries, e)
+		}
+	}
+	return filtered
+}
+
+// FilterLevel returns the entries at exactly level.
+func (o *ObservedLogs) FilterLevel(level logh.LoghLevel) *ObservedLogs {
+	return o.Filter(func(e logh.Entry) bool { return e.Level == level })
+}
+
+// FilterMessage returns the entries whose message is msg.
+func (o *ObservedLogs) FilterMessage(msg string) *ObservedLogs {
+	return o.Filter(func(e logh.Entry) bool { return e.Message == msg })
+}
+
+// FilterMessageSnippet returns the entries whose message contains snippet.
+func (o *ObservedLogs) FilterMessageSnippet(snippet string) *ObservedLogs {
+	return o.Filter(func(e logh.Entry) bool { return strings.Contains(e.Message, snippet) })
+}
+
+// FilterField returns the entries with a field equal to f.
+func (o *ObservedLogs) FilterField(f logh.Field) *ObservedLogs {
+	return o.Filter(func(e logh.Entry) bool {
+		for _, v := range e.Fields {
+			if v.Key == f.Key && reflect.DeepEqual(v.Value, f.Value) {
+				return true
+			}
+		}
+		return false
+	})
+}
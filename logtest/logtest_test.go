@@ -0,0 +1,81 @@
+package logtest
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/paulfdunn/logh"
+)
+
+// TestNew tests that entries at or above the level are recorded with their level,
+// message, fields and caller, and that the filters select them.
+func TestNew(t *testing.T) {
+	t.Parallel()
+	l, logs := New(t, logh.Info)
+
+	_, file, line, _ := runtime.Caller(0)
+	l.Println(logh.Debug, "filtered")
+	l.Printw(logh.Info, "request done", "status", 200)
+	l.Printf(logh.Error, "request %s failed", "b")
+	l.Printw(logh.Info, "request done", "status", 500)
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("incorrect entries, received: %+v", entries)
+	}
+	e := entries[0]
+	if e.Level != logh.Info || e.LevelName != "info" || e.Message != "request done" ||
+		!reflect.DeepEqual(e.Fields, []logh.Field{{Key: "status", Value: 200}}) {
+		t.Errorf("incorrect entry, received: %+v", e)
+	}
+	if e.File != file || e.Line != line+2 || e.Function != "github.com/paulfdunn/logh/logtest.TestNew" {
+		t.Errorf("incorrect caller, received: %s:%d %s", e.File, e.Line, e.Function)
+	}
+	if e.Time.IsZero() {
+		t.Errorf("no time")
+	}
+
+	if n := logs.FilterLevel(logh.Info).Len(); n != 2 {
+		t.Errorf("incorrect FilterLevel count: %d", n)
+	}
+	if m := logs.FilterLevel(logh.Error).Messages(); !reflect.DeepEqual(m, []string{"request b failed"}) {
+		t.Errorf("incorrect FilterLevel messages: %v", m)
+	}
+	if n := logs.FilterMessage("request done").Len(); n != 2 {
+		t.Errorf("incorrect FilterMessage count: %d", n)
+	}
+	if n := logs.FilterMessageSnippet("failed").Len(); n != 1 {
+		t.Errorf("incorrect FilterMessageSnippet count: %d", n)
+	}
+	if n := logs.FilterField(logh.Field{Key: "status", Value: 500}).Len(); n != 1 {
+		t.Errorf("incorrect FilterField count: %d", n)
+	}
+	if n := len(logs.TakeAll()); n != 3 || logs.Len() != 0 {
+		t.Errorf("incorrect TakeAll, taken: %d, remaining: %d", n, logs.Len())
+	}
+}
+
+// TestObserve tests that a registered logger is observed until the test ends, and still
+// writes its output.
+func TestObserve(t *testing.T) {
+	r := logh.NewRegistry(nil)
+	err := r.New("db", filepath.Join(t.TempDir(), "db.txt"), logh.DefaultLevels, logh.Debug, 0, 10, 10000)
+	if err != nil {
+		t.Fatalf("error with New, error: %v", err)
+	}
+	defer r.ShutdownAll()
+	l := r.Get("db")
+
+	var logs *ObservedLogs
+	t.Run("observed", func(t *testing.T) {
+		logs = Observe(t, l)
+		l.Println(logh.Warning, "slow query")
+	})
+	l.Println(logh.Warning, "after the test")
+
+	if m := logs.Messages(); !reflect.DeepEqual(m, []string{"slow query"}) {
+		t.Errorf("incorrect messages: %v", m)
+	}
+}
@@ -0,0 +1,62 @@
+package logtest
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/paulfdunn/logh"
+)
+
+// tbWriter writes each entry to tb.Logf until the test ends.
+type tbWriter struct {
+	mu   sync.Mutex
+	tb   testing.TB
+	done bool
+}
+
+func (w *tbWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// Logging to tb after the test ends panics.
+	if !w.done {
+		w.tb.Logf("%s", strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+// NewT registers a Logger named name, with logh.DefaultLevels and logging at level, in the
+// default logh Registry, that writes each entry to tb.Logf. Libraries logging to
+// logh.Get(name) or logh.Map[name] then log into the running test, interleaved with its
+// go test -v output and only shown for failed tests otherwise. tb.Logf attributes every
+// entry to logtest, so entries include the file and line of their call site
+// (log.Lshortfile). The Logger is removed when tb ends, and entries written to it after
+// that are dropped. Tests using the same name must not run in parallel.
+//
+//	func TestQuery(t *testing.T) {
+//		logtest.NewT(t, "db", logh.Debug)
+//		// info: db.go:42: connected
+//		...
+//	}
+func NewT(tb testing.TB, name string, level logh.LoghLevel) *logh.Logger {
+	tb.Helper()
+	err := logh.New(name, "", logh.DefaultLevels, level, log.Lshortfile, logh.DefaultCheckLogSize,
+		logh.DefaultMaxLogSize)
+	if err != nil {
+		tb.Fatalf("error with New, error: %v", err)
+	}
+	l := logh.Get(name)
+	w := &tbWriter{tb: tb}
+	l.SetOutput(w)
+	tb.Cleanup(func() {
+		w.mu.Lock()
+		w.done = true
+		w.mu.Unlock()
+		// A later New may have replaced the Logger.
+		if logh.Get(name) == l {
+			logh.Remove(name)
+		}
+	})
+	return l
+}
@@ -0,0 +1,45 @@
+package logtest
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/paulfdunn/logh"
+)
+
+// recordingTB records Logf calls, and is otherwise the embedded testing.TB.
+type recordingTB struct {
+	testing.TB
+	logs []string
+}
+
+func (r *recordingTB) Logf(format string, args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprintf(format, args...))
+}
+
+// TestNewT tests that entries to the named logger are written to the test log with their
+// call site, and that the logger is removed, and further entries dropped, when the test
+// ends.
+func TestNewT(t *testing.T) {
+	rtb := &recordingTB{}
+	var l *logh.Logger
+	var line int
+	t.Run("logged", func(t *testing.T) {
+		rtb.TB = t
+		l = NewT(rtb, "tlog", logh.Info)
+		_, _, line, _ = runtime.Caller(0)
+		logh.Get("tlog").Println(logh.Info, "connected")
+		logh.Get("tlog").Println(logh.Debug, "filtered")
+	})
+	l.Println(logh.Info, "after the test")
+
+	expected := []string{fmt.Sprintf("info: tlog_test.go:%d: connected", line+1)}
+	if !reflect.DeepEqual(rtb.logs, expected) {
+		t.Errorf("incorrect logs, received: %q, expected: %q", rtb.logs, expected)
+	}
+	if logh.Lookup("tlog") != nil {
+		t.Errorf("logger not removed")
+	}
+}
@@ -0,0 +1,53 @@
+package logh
+
+import (
+	"runtime"
+	"time"
+)
+
+// Entry is a log entry as passed to the function set with SetObserver.
+type Entry struct {
+	Level LoghLevel
+	// LevelName is the name of Level in the levels of the Logger.
+	LevelName string
+	Time      time.Time
+	Message   string
+	Fields    []Field
+	// File, Line and Function are the call site.
+	File     string
+	Line     int
+	Function string
+}
+
+// SetObserver calls f with each entry written by l, after level filtering, sampling and
+// rate limiting, and before deduplication; entries are observed whether or not l has an
+// output. f is called with l locked, so must not log to l. A nil f removes the observer.
+// SetObserver returns the prior observer, so it can be restored.
+func (l *Logger) SetObserver(f func(Entry)) func(Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	prior := l.observer
+	l.observer = f
+	return prior
+}
+
+// observe calls the observer, if any, with the entry from the call site skip frames above
+// observe; skip 1 is the function calling observe. l.mu must be held.
+func (l *Logger) observe(skip int, level LoghLevel, msg string, fields []Field) {
+	if l.observer == nil {
+		return
+	}
+	pcs := [1]uintptr{}
+	runtime.Callers(skip+1, pcs[:])
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	l.observer(Entry{
+		Level:     level,
+		LevelName: l.levels[level],
+		Time:      l.now(),
+		Message:   msg,
+		Fields:    fields,
+		File:      frame.File,
+		Line:      frame.Line,
+		Function:  frame.Function,
+	})
+}
@@ -0,0 +1,46 @@
+package logh
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSetObserver tests that written entries, including recovered panics, are observed,
+// and that the prior observer is returned.
+func TestSetObserver(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Info, 0, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+
+	var entries []Entry
+	observer := func(e Entry) { entries = append(entries, e) }
+	if prior := lg.SetObserver(observer); prior != nil {
+		t.Errorf("incorrect prior observer")
+	}
+	lg.Println(Debug, "filtered")
+	lg.Printw(Info, "started", "port", 8080)
+	func() {
+		defer RecoverAndLog(loggerName)
+		panicker("boom")
+	}()
+	if prior := lg.SetObserver(nil); prior == nil {
+		t.Errorf("prior observer not returned")
+	}
+	lg.Println(Info, "not observed")
+	lg.Shutdown()
+
+	if len(entries) != 2 {
+		t.Fatalf("incorrect entries, received: %+v", entries)
+	}
+	if e := entries[0]; e.Level != Info || e.Message != "started" || len(e.Fields) != 1 ||
+		!strings.HasSuffix(e.File, "observer_test.go") {
+		t.Errorf("incorrect entry, received: %+v", e)
+	}
+	if e := entries[1]; e.Level != Error || !strings.HasPrefix(e.Message, "panic: boom\n") ||
+		e.Function != "github.com/paulfdunn/logh.panicker" {
+		t.Errorf("incorrect panic entry, received: %+v", e)
+	}
+}
@@ -0,0 +1,66 @@
+package logh
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestPrintFamily tests that Print and Println match fmt.Sprint and fmt.Sprintln for mixed
+// argument types, and that Printw renders fields.
+func TestPrintFamily(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 10, 10000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+
+	lg := Map[loggerName]
+	lg.Println(Info, "count", 3)
+	lg.Println(Info, 1, 2.5, true, nil, []int{1, 2})
+	lg.Print(Info, "count", 3)
+	lg.Print(Info, 1, 2, "a", "b")
+	lg.Printf(Info, "%s=%d", "count", 3)
+	lg.Printw(Info, "request done", "status", 200, "path", "/a b", Field{"ok", true})
+	lg.Printw(Info, "bad keys", 5, "dangling")
+	lg.Shutdown()
+
+	expected := "info: count 3\n" +
+		"info: 1 2.5 true <nil> [1 2]\n" +
+		"info: count3\n" +
+		"info: 1 2ab\n" +
+		"info: count=3\n" +
+		"info: request done status=200 path=\"/a b\" ok=true\n" +
+		"info: bad keys !BADKEY=5 !BADKEY=dangling\n"
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
+
+// TestOutsideLevels tests that entries at levels outside the levels of the logger,
+// including negative levels, are not written, and an error is written in their place.
+func TestOutsideLevels(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 10, 10000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+
+	lg := Map[loggerName]
+	lg.Printf(-1, "negative %d", 1)
+	lg.Println(LoghLevel(len(DefaultLevels)), "too high")
+	lg.Println(Info, "in range")
+	if lg.Enabled(-1) {
+		t.Errorf("negative level enabled")
+	}
+	lg.Shutdown()
+
+	expected := "input level was outside range, level:-1, len(levels)-1:4\n" +
+		"input level was outside range, level:5, len(levels)-1:4\n" +
+		"info: in range\n"
+	logString, _ := readTestLog(testLog, 0)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
@@ -0,0 +1,200 @@
+package logh
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// rateLimiter holds the SetRateLimit policy and the state for each call site.
+type rateLimiter struct {
+	n   int
+	per time.Duration
+	// sites is keyed by file:line, rather than program counter, as inlining can give one
+	// call site several program counters. pcSites caches the key for each program counter.
+	sites   map[string]*rateLimitSite
+	pcSites map[uintptr]string
+}
+
+// rateLimitSite counts the entries of one call site in the current window.
+type rateLimitSite struct {
+	end     time.Time
+	count   int
+	dropped int
+	// level is the level of the last entry dropped.
+	level LoghLevel
+}
+
+// deduplicator holds the SetDeduplicate policy and the last entry written.
+type deduplicator struct {
+	flush time.Duration
+	timer *time.Timer
+	// flushAt is the Clock time the summary is due, flush after the first repeat.
+	flushAt time.Time
+
+	// ok is true when level and entry hold the last entry written.
+	ok      bool
+	level   LoghLevel
+	entry   string
+	repeats int
+}
+
+// SetRateLimit limits each call site to n entries per interval; further entries from that
+// call site are dropped until the interval ends. The number dropped is written before the
+// next entry written from that call site, and at Sync, Shutdown and reloads. Rate limiting applies after the level filter and
+// sampling, and before formatting. An n of 0 or less, or an interval of 0 or less, removes
+// rate limiting.
+func (l *Logger) SetRateLimit(n int, interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushRateLimited()
+	if n <= 0 || interval <= 0 {
+		l.rateLimiter = nil
+		return
+	}
+	l.rateLimiter = &rateLimiter{n: n, per: interval,
+		sites: map[string]*rateLimitSite{}, pcSites: map[uintptr]string{}}
+}
+
+// SetDeduplicate collapses consecutive identical entries, same level, message and fields,
+// into the first entry and a "last message repeated N times" entry. The summary is written
+// when a different entry is written, at Shutdown, and, if flush is greater than 0, flush
+// after the first repeat; by a timer, or at the next entry if the Clock has passed flush.
+// enable false removes deduplication, writing any pending summary.
+func (l *Logger) SetDeduplicate(enable bool, flush time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushRepeats()
+	if !enable {
+		l.deduplicator = nil
+		return
+	}
+	l.deduplicator = &deduplicator{flush: flush}
+}
+
+// allow returns true if the rate limit for the call site skip frames above allow permits
+// the entry; skip 1 is the function calling allow. l.mu must be held.
+func (l *Logger) allow(skip int, level LoghLevel) bool {
+	rl := l.rateLimiter
+	if rl == nil {
+		return true
+	}
+
+	pcs := [1]uintptr{}
+	runtime.Callers(skip+1, pcs[:])
+	key, ok := rl.pcSites[pcs[0]]
+	if !ok {
+		frame, _ := runtime.CallersFrames(pcs[:]).Next()
+		key = frame.File + ":" + strconv.Itoa(frame.Line)
+		rl.pcSites[pcs[0]] = key
+	}
+	site := rl.sites[key]
+	if site == nil {
+		site = &rateLimitSite{}
+		rl.sites[key] = site
+	}
+
+	now := l.now()
+	if !now.Before(site.end) {
+		// The summary is at the level of the entries dropped, not of this entry.
+		if site.dropped > 0 && int(site.level) < len(l.loggers) && l.loggers[site.level] != nil {
+			l.write(skip+1, site.level, fmt.Sprintf("rate limited %d entries in %s", site.dropped, rl.per))
+		}
+		site.end = now.Add(rl.per)
+		site.count = 0
+		site.dropped = 0
+	}
+	if site.count < rl.n {
+		site.count++
+		return true
+	}
+	site.dropped++
+	site.level = level
+	return false
+}
+
+// flushRateLimited writes, then clears, the number of entries dropped from each call site
+// in its current interval, so they are not lost at Shutdown or Sync. The call site is
+// named in the entry, as it is not the caller. l.mu must be held.
+func (l *Logger) flushRateLimited() {
+	rl := l.rateLimiter
+	if rl == nil {
+		return
+	}
+	keys := make([]string, 0, len(rl.sites))
+	for key, site := range rl.sites {
+		if site.dropped > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		site := rl.sites[key]
+		if int(site.level) < len(l.loggers) && l.loggers[site.level] != nil {
+			l.emit(2, site.level, fmt.Sprintf("rate limited %d entries in %s from %s",
+				site.dropped, rl.per, filepath.Base(key)))
+		}
+		site.dropped = 0
+	}
+}
+
+// write writes entry at level, applying deduplication. calldepth is as for log.Output,
+// from the caller of write. l.mu must be held.
+func (l *Logger) write(calldepth int, level LoghLevel, entry string) {
+	d := l.deduplicator
+	if d == nil {
+		l.emit(calldepth+1, level, entry)
+		return
+	}
+
+	now := l.now()
+	if d.repeats > 0 && d.flush > 0 && !now.Before(d.flushAt) {
+		l.writeRepeats(calldepth + 1)
+	}
+	if d.ok && d.entry == entry && d.level == level {
+		d.repeats++
+		if d.repeats == 1 && d.flush > 0 {
+			d.flushAt = now.Add(d.flush)
+			d.timer = time.AfterFunc(d.flush, func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+				if l.deduplicator == d {
+					l.flushRepeats()
+				}
+			})
+		}
+		return
+	}
+
+	l.writeRepeats(calldepth + 1)
+	d.ok, d.level, d.entry = true, level, entry
+	l.emit(calldepth+1, level, entry)
+}
+
+// flushRepeats writes the repeated entry summary, if any; l.mu must be held.
+func (l *Logger) flushRepeats() {
+	l.writeRepeats(2)
+}
+
+// writeRepeats implements flushRepeats, attributing the summary to the call site of the
+// entry that ends the repeats. calldepth is as for log.Output, from the caller of
+// writeRepeats. l.mu must be held.
+func (l *Logger) writeRepeats(calldepth int) {
+	d := l.deduplicator
+	if d == nil {
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.repeats > 0 && int(d.level) < len(l.loggers) && l.loggers[d.level] != nil {
+		l.emit(calldepth+1, d.level, fmt.Sprintf("last message repeated %d times", d.repeats))
+	}
+	d.repeats = 0
+	// A repeat after the summary is logged again, rather than counted towards a new summary.
+	d.ok = false
+}
@@ -0,0 +1,176 @@
+package logh
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRateLimit tests that each call site is limited independently, and that dropped
+// entries are reported from the call site.
+func TestRateLimit(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, log.Lshortfile, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	interval := 50 * time.Millisecond
+	Map[loggerName].SetRateLimit(3, interval)
+
+	_, _, line, _ := runtime.Caller(0)
+	burst := func() {
+		for i := 0; i < 10; i++ {
+			Map[loggerName].Printf(Error, "site one %d", i)
+			Map[loggerName].Printf(Error, "site two %d", i)
+		}
+	}
+	start := time.Now()
+	burst()
+	if time.Since(start) >= interval {
+		t.Skip("interval elapsed during the burst")
+	}
+	time.Sleep(interval)
+	burst()
+	Map[loggerName].Shutdown()
+
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	if strings.Count(logString, "site one") != 6 || strings.Count(logString, "site two") != 6 {
+		t.Errorf("incorrect rate limit")
+	}
+	for _, v := range []int{line + 3, line + 4} {
+		expected := fmt.Sprintf("error: ratelimit_test.go:%d: rate limited 7 entries in 50ms\n", v)
+		if strings.Count(logString, expected) != 1 {
+			t.Errorf("dropped entries not reported, expected: %s", expected)
+		}
+	}
+}
+
+// TestDeduplicate tests that consecutive identical entries are collapsed, and the summary
+// written on a different entry, by the flush timer, and at Shutdown.
+func TestDeduplicate(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetDeduplicate(true, 20*time.Millisecond)
+
+	lg.Println(Debug, "")
+	for i := 0; i < 5; i++ {
+		lg.Println(Error, "dependency down")
+	}
+	lg.Println(Error, "dependency down", "again")
+	lg.Println(Error, "dependency down", "again")
+	lg.Println(Info, "dependency down", "again")
+	lg.Println(Info, "dependency down", "again")
+	lg.Println(Info, "dependency down", "again")
+	time.Sleep(100 * time.Millisecond)
+	lg.Println(Info, "dependency down", "again")
+	lg.Println(Info, "dependency down", "again")
+	lg.Shutdown()
+
+	expected := "debug: \n" +
+		"error: dependency down\n" +
+		"error: last message repeated 4 times\n" +
+		"error: dependency down again\n" +
+		"error: last message repeated 1 times\n" +
+		"info: dependency down again\n" +
+		"info: last message repeated 2 times\n" +
+		"info: dependency down again\n" +
+		"info: last message repeated 1 times\n"
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
+
+// TestDeduplicateReconfigure tests that a pending summary is written before a reload
+// removes its level.
+func TestDeduplicateReconfigure(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetDeduplicate(true, 0)
+
+	lg.Println(Error, "dup")
+	lg.Println(Error, "dup")
+	err = ApplyConfig(Config{Loggers: map[string]LoggerConfig{
+		loggerName: {Path: testLog, Levels: []string{"low", "high"}, Flags: []string{}},
+	}})
+	if err != nil {
+		t.Errorf("error with ApplyConfig, error: %v", err)
+	}
+	lg.Println(0, "x")
+	lg.Shutdown()
+
+	expected := "error: dup\n" +
+		"error: last message repeated 1 times\n" +
+		"low: x\n"
+	logString, _ := readTestLog(testLog, 0)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
+
+// TestRateLimitFlush tests that entries dropped in the last interval are reported, with
+// their call site, at Shutdown.
+func TestRateLimitFlush(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetRateLimit(1, time.Minute)
+
+	_, _, line, _ := runtime.Caller(0)
+	for i := 0; i < 3; i++ {
+		lg.Printf(Error, "flood %d", i)
+	}
+	lg.Shutdown()
+
+	expected := fmt.Sprintf("error: flood 0\n"+
+		"error: rate limited 2 entries in 1m0s from ratelimit_test.go:%d\n", line+2)
+	logString, _ := readTestLog(testLog, 0)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
+
+// TestRateLimitLevel tests that the summary is written at the level of the entries
+// dropped, rather than of the next entry from the call site.
+func TestRateLimitLevel(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, log.Lshortfile, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	clock := &testClock{now: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)}
+	lg.SetClock(clock)
+	lg.SetRateLimit(1, time.Minute)
+
+	_, _, line, _ := runtime.Caller(0)
+	for _, level := range []LoghLevel{Error, Error, Debug} {
+		lg.Printf(level, "entry")
+		clock.advance(40 * time.Second)
+	}
+	lg.Shutdown()
+
+	expected := fmt.Sprintf("error: ratelimit_test.go:%[1]d: entry\n"+
+		"error: ratelimit_test.go:%[1]d: rate limited 1 entries in 1m0s\n"+
+		"debug: ratelimit_test.go:%[1]d: entry\n", line+2)
+	logString, _ := readTestLog(testLog, 0)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
@@ -0,0 +1,67 @@
+package logh
+
+import (
+	"bytes"
+	"log"
+)
+
+// recorder is a ring buffer of formatted entries that were below the logging level.
+type recorder struct {
+	trigger LoghLevel
+	entries [][]byte
+	// next is the index for the next entry, and count the number of entries held.
+	next  int
+	count int
+
+	buf    bytes.Buffer
+	logger *log.Logger
+}
+
+// SetFlightRecorder keeps the last size entries that are below the logging level, and
+// would otherwise be dropped, in memory. When an entry at or above trigger is written, the
+// kept entries are written first, oldest first and with their original time and caller,
+// so the entry comes with its recent history. Kept entries are formatted, so they have the
+// cost of a written entry without the I/O. A size of 0 or less removes the recorder.
+func (l *Logger) SetFlightRecorder(size int, trigger LoghLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if size <= 0 {
+		l.recorder = nil
+		return
+	}
+	r := &recorder{trigger: trigger, entries: make([][]byte, size)}
+	r.logger = log.New(&r.buf, "", 0)
+	l.recorder = r
+}
+
+// record formats entry as it would be written, and keeps it. calldepth is as for
+// log.Output, from the caller of record. l.mu must be held.
+func (l *Logger) record(calldepth int, level LoghLevel, entry string) {
+	r := l.recorder
+	r.buf.Reset()
+	r.logger.SetPrefix(l.prefix(level))
+	r.logger.SetFlags(l.logFlags())
+	r.logger.Output(calldepth+1, entry)
+
+	// Reuse the slot's memory once the ring is full.
+	r.entries[r.next] = append(r.entries[r.next][:0], r.buf.Bytes()...)
+	r.next = (r.next + 1) % len(r.entries)
+	if r.count < len(r.entries) {
+		r.count++
+	}
+}
+
+// replayRecorder writes, then clears, the kept entries if level is at or above the
+// trigger; l.mu must be held.
+func (l *Logger) replayRecorder(level LoghLevel) {
+	r := l.recorder
+	if r == nil || level < r.trigger || r.count == 0 {
+		return
+	}
+	w := l.writer()
+	start := (r.next - r.count + len(r.entries)) % len(r.entries)
+	for i := 0; i < r.count; i++ {
+		w.Write(r.entries[(start+i)%len(r.entries)])
+	}
+	r.count = 0
+}
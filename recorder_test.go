@@ -0,0 +1,46 @@
+package logh
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"testing"
+)
+
+// TestFlightRecorder tests that the last entries below the level are written, in order,
+// before an entry at the trigger level, and only once.
+func TestFlightRecorder(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Warning, log.Lshortfile, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetFlightRecorder(3, Error)
+
+	_, _, line, _ := runtime.Caller(0)
+	for i := 0; i < 5; i++ {
+		lg.Printf(Debug, "step %d", i)
+	}
+	lg.Println(Info, "done")
+	lg.Println(Warning, "below the trigger")
+	lg.Println(Error, "failed")
+	lg.Println(Debug, "after")
+	lg.Println(Error, "failed again")
+	lg.Shutdown()
+
+	// The warning is at the logging level, so is written when logged, not kept.
+	expected := fmt.Sprintf("warning: recorder_test.go:%[3]d: below the trigger\n"+
+		"debug: recorder_test.go:%[1]d: step 3\n"+
+		"debug: recorder_test.go:%[1]d: step 4\n"+
+		"info: recorder_test.go:%[2]d: done\n"+
+		"error: recorder_test.go:%[4]d: failed\n"+
+		"debug: recorder_test.go:%[5]d: after\n"+
+		"error: recorder_test.go:%[6]d: failed again\n",
+		line+2, line+4, line+5, line+6, line+7, line+8)
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
@@ -0,0 +1,44 @@
+package logh
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// RecoverAndLog recovers a panic and writes it, with the stack trace of the panicking
+// goroutine, to the logger Get(name) at its highest level, then syncs that logger. Call it
+// with defer; it must be the deferred function to recover the panic:
+//
+//	defer logh.RecoverAndLog("app")
+//
+// If there is no logger for name, and no fallback, the panic is written to STDERR.
+func RecoverAndLog(name string) {
+	if r := recover(); r != nil {
+		logPanic(name, r)
+	}
+}
+
+// RecoverAndRepanic is RecoverAndLog, but panics again with the recovered value after it
+// is logged, so the crash is recorded and the process still terminates.
+func RecoverAndRepanic(name string) {
+	if r := recover(); r != nil {
+		logPanic(name, r)
+		panic(r)
+	}
+}
+
+// Go runs f in a new goroutine, logging any panic from f as RecoverAndLog. The goroutine
+// ends after the panic is logged; the process continues.
+func Go(name string, f func()) {
+	go func() {
+		defer RecoverAndLog(name)
+		f()
+	}()
+}
+
+// logPanic writes the recovered value r and the stack trace.
+func logPanic(name string, r interface{}) {
+	// Output attributes the entry to the panicking function: frames above Output are
+	// writeHighest, logPanic, RecoverAndLog, the runtime panic, and the function that panicked.
+	Get(name).writeHighest(5, fmt.Sprintf("panic: %v\n%s", r, debug.Stack()))
+}
@@ -0,0 +1,57 @@
+package logh
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestRecoverAndLog tests that panics are logged with a stack at the highest level, for
+// RecoverAndLog, RecoverAndRepanic and Go.
+func TestRecoverAndLog(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Error, 0, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+
+	func() {
+		defer RecoverAndLog(loggerName)
+		panicker("recovered")
+	}()
+
+	func() {
+		defer func() {
+			if r := recover(); r != "repanicked" {
+				t.Errorf("incorrect repanic: %v", r)
+			}
+		}()
+		defer RecoverAndRepanic(loggerName)
+		panicker("repanicked")
+	}()
+
+	Go(loggerName, func() {
+		panicker("in goroutine")
+	})
+	// Each entry is synced, so the log can be read before Shutdown.
+	waitFor(t, func() bool {
+		logString, _ := readTestLog(testLog, 0)
+		return strings.Contains(logString, "error: panic: in goroutine\n")
+	})
+
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	for _, v := range []string{"recovered", "repanicked", "in goroutine"} {
+		if !strings.Contains(logString, "error: panic: "+v+"\ngoroutine ") {
+			t.Errorf("panic not logged: %s", v)
+		}
+	}
+	if strings.Count(logString, "logh.panicker") != 3 {
+		t.Errorf("stack traces missing the panicking function")
+	}
+	Map[loggerName].Shutdown()
+}
+
+func panicker(v string) {
+	panic(v)
+}
@@ -0,0 +1,154 @@
+package logh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry is a set of named Loggers. The package level functions New, Get, ShutdownAll,
+// etc. use a default Registry, whose loggers are in Map. A separate Registry keeps an
+// isolated set of loggers, for example per tenant, or per test so tests can run in
+// parallel.
+type Registry struct {
+	mu      sync.RWMutex
+	loggers map[string]*Logger
+	// fallback is the name of the Logger Get returns for missing names; see SetFallback.
+	fallback string
+	// output is used by loggers without a file path.
+	output io.Writer
+}
+
+var (
+	// discardLogger is returned by Get when there is no Logger to return.
+	discardLogger = &Logger{discard: true}
+)
+
+// NewRegistry returns an empty Registry. Loggers in the Registry created without a file
+// path write to output; nil uses STDOUT.
+func NewRegistry(output io.Writer) *Registry {
+	if output == nil {
+		output = os.Stdout
+	}
+	return &Registry{loggers: map[string]*Logger{}, output: output}
+}
+
+// Get returns the Logger for name, resolved as Lookup. If there is none, the fallback
+// Logger (see SetFallback) is returned if it exists, otherwise a Logger that discards
+// all entries. Get never returns nil, and is safe to call concurrently with New and Remove.
+func Get(name string) *Logger {
+	return defaultRegistry.Get(name)
+}
+
+// Get is Get for the loggers in r.
+func (r *Registry) Get(name string) *Logger {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if l := r.lookup(name); l != nil {
+		return l
+	}
+	if r.fallback != "" {
+		if l := r.lookup(r.fallback); l != nil {
+			return l
+		}
+	}
+	return discardLogger
+}
+
+// SetFallback sets the name of the Logger that Get returns for names that do not exist,
+// so entries from libraries logging to unconfigured names are kept. The fallback is
+// resolved on each call to Get, so it need not exist yet. An empty name restores the
+// default of discarding.
+func SetFallback(name string) {
+	defaultRegistry.SetFallback(name)
+}
+
+// SetFallback is SetFallback for the loggers in r.
+func (r *Registry) SetFallback(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = name
+}
+
+// Names returns the sorted names of all loggers in Map.
+func Names() []string {
+	return defaultRegistry.Names()
+}
+
+// Names returns the sorted names of all loggers in r.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.loggers))
+	for name := range r.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Remove shuts down the logger name and removes it from Map. Removing a name that does
+// not exist is not an error.
+func Remove(name string) error {
+	return defaultRegistry.Remove(name)
+}
+
+// Remove is Remove for the loggers in r.
+func (r *Registry) Remove(name string) error {
+	r.mu.Lock()
+	l, ok := r.loggers[name]
+	delete(r.loggers, name)
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if err := l.Shutdown(); err != nil {
+		return fmt.Errorf("removing logger:%s, error:%v", name, err)
+	}
+	return nil
+}
+
+// Lookup returns the Logger for a hierarchical, dot separated, name. If name is not in
+// Map, the nearest ancestor is returned: for "db.pool.conn", "db.pool" then "db". So a
+// child without its own configuration logs to its parent's file at its parent's level,
+// and changing the parent's level applies to the child. A child created with New is
+// independent of its parent. nil is returned if neither name nor an ancestor exists;
+// printing to a nil Logger does nothing.
+func Lookup(name string) *Logger {
+	return defaultRegistry.Lookup(name)
+}
+
+// Lookup is Lookup for the loggers in r.
+func (r *Registry) Lookup(name string) *Logger {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lookup(name)
+}
+
+// lookup implements Lookup; r.mu must be held.
+func (r *Registry) lookup(name string) *Logger {
+	for {
+		if l, ok := r.loggers[name]; ok {
+			return l
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+	}
+}
+
+// loggersSnapshot returns a copy of the loggers in r, so they can be used without r.mu.
+func (r *Registry) loggersSnapshot() map[string]*Logger {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	loggers := make(map[string]*Logger, len(r.loggers))
+	for k, v := range r.loggers {
+		loggers[k] = v
+	}
+	return loggers
+}
@@ -0,0 +1,119 @@
+package logh
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// TestLookup tests that children without configuration resolve to their nearest ancestor,
+// and that a level change on the parent cascades to them.
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	dbLog := filepath.Join(dir, "db.txt")
+	connLog := filepath.Join(dir, "conn.txt")
+	defer ShutdownAll()
+	if err := New("db", dbLog, DefaultLevels, Info, 0, 10, 10000); err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	if err := New("db.pool.conn", connLog, DefaultLevels, Error, 0, 10, 10000); err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+
+	if Lookup("db.pool") != Map["db"] || Lookup("db.query.slow") != Map["db"] ||
+		Lookup("db.pool.conn") != Map["db.pool.conn"] || Lookup("db.pool.conn.tls") != Map["db.pool.conn"] {
+		t.Errorf("incorrect ancestor")
+	}
+	if Lookup("dbx") != nil || Lookup("cache.db") != nil || Lookup("") != nil {
+		t.Errorf("unrelated name resolved")
+	}
+
+	Lookup("db.pool").Println(Debug, "filtered")
+	Map["db"].SetLevel(Debug)
+	Lookup("db.pool").Println(Debug, "cascaded")
+	Lookup("db.pool.conn").Println(Debug, "independent")
+	Lookup("missing.child").Println(Error, "nil logger")
+	ShutdownAll()
+
+	dbString, _ := readTestLog(dbLog, 0)
+	connString, _ := readTestLog(connLog, 0)
+	if dbString != "debug: cascaded\n" || connString != "" {
+		t.Errorf("incorrect output, db: %s, conn: %s", dbString, connString)
+	}
+}
+
+// TestGet tests Get for registered, missing and fallback names, and Names and Remove.
+func TestGet(t *testing.T) {
+	dir := t.TempDir()
+	appLog := filepath.Join(dir, "app.txt")
+	fallbackLog := filepath.Join(dir, "fallback.txt")
+	ShutdownAll()
+	defer ShutdownAll()
+	defer SetFallback("")
+	if err := New("app", appLog, DefaultLevels, Debug, 0, 10, 10000); err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+
+	if Get("app") != Map["app"] || Get("app.child") != Map["app"] {
+		t.Errorf("registered logger not returned")
+	}
+	missing := Get("missing")
+	if missing == nil || missing.Enabled(Error) {
+		t.Errorf("missing logger not a discard logger")
+	}
+	missing.Println(Error, "discarded")
+
+	SetFallback("fallback")
+	if Get("missing") != discardLogger {
+		t.Errorf("fallback returned before it exists")
+	}
+	if err := New("fallback", fallbackLog, DefaultLevels, Debug, 0, 10, 10000); err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	Get("missing").Println(Info, "to fallback")
+
+	if names := Names(); len(names) != 2 || names[0] != "app" || names[1] != "fallback" {
+		t.Errorf("incorrect names: %v", names)
+	}
+	if err := Remove("fallback"); err != nil {
+		t.Errorf("error with Remove, error: %v", err)
+	}
+	if err := Remove("fallback"); err != nil {
+		t.Errorf("error removing missing name, error: %v", err)
+	}
+	if names := Names(); len(names) != 1 || Get("missing") != discardLogger {
+		t.Errorf("logger not removed, names: %v", names)
+	}
+
+	fallbackString, _ := readTestLog(fallbackLog, 0)
+	if fallbackString != "info: to fallback\n" {
+		t.Errorf("incorrect fallback output: %s", fallbackString)
+	}
+}
+
+// TestRegistryIsolation tests that loggers of the same name in separate Registries, and the
+// default Registry, are independent, including when used in parallel.
+func TestRegistryIsolation(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		i := i
+		t.Run(fmt.Sprintf("registry%d", i), func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			r := NewRegistry(&buf)
+			if err := r.New("shared", "", DefaultLevels, Debug, 0, 10, 10000); err != nil {
+				t.Errorf("error with New, error: %v", err)
+			}
+			r.Get("shared").Printf(Info, "registry %d", i)
+			if names := r.Names(); len(names) != 1 || Lookup("shared") != nil {
+				t.Errorf("registry not isolated, names: %v", names)
+			}
+			if err := r.ShutdownAll(); err != nil {
+				t.Errorf("error with ShutdownAll, error: %v", err)
+			}
+			if buf.String() != fmt.Sprintf("info: registry %d\n", i) || r.Get("shared") != discardLogger {
+				t.Errorf("incorrect output: %s", buf.String())
+			}
+		})
+	}
+}
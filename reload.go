@@ -0,0 +1,140 @@
+package logh
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WatchConfig calls LoadConfig for path, then polls path every interval, which must be
+// greater than 0, and applies the configuration again when the contents change; see
+// ApplyConfig. Errors reading or applying a changed file are passed to onError, if not
+// nil. A file that cannot be read or parsed, or is invalid, leaves the prior configuration
+// in effect; errors found while applying are as for ApplyConfig. Call stop to end
+// polling; stop waits for any reload in progress.
+func WatchConfig(path string, interval time.Duration, onError func(error)) (stop func(), err error) {
+	return defaultRegistry.WatchConfig(path, interval, onError)
+}
+
+// WatchConfig is WatchConfig for the loggers in r.
+func (r *Registry) WatchConfig(path string, interval time.Duration, onError func(error)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("watching config file, error:interval must be greater than 0, interval:%s", interval)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file, error:%v", err)
+	}
+	cfg, err := ParseConfig(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.ApplyConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := b
+		var lastErr []byte
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				if onError != nil {
+					onError(fmt.Errorf("reading config file, error:%v", err))
+				}
+				continue
+			}
+			// Skip unchanged files, and report a bad file only once rather than every poll.
+			if bytes.Equal(b, last) || bytes.Equal(b, lastErr) {
+				continue
+			}
+			cfg, err := ParseConfig(b)
+			if err == nil {
+				err = r.ApplyConfig(cfg)
+			}
+			if err != nil {
+				lastErr = b
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			last, lastErr = b, nil
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}, nil
+}
+
+// reconfigure applies rc to l in place, so pointers to l remain valid. Entries written
+// concurrently wait for the change, then are written with the new configuration. A shut
+// down Logger stays shut down; no file is opened for it.
+func (l *Logger) reconfigure(rc resolvedConfig) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Pending summaries are written with the levels they were counted at.
+	l.flushSampled()
+	l.flushRateLimited()
+	l.flushRepeats()
+	shutDown := len(l.loggers) == 0 || l.loggers[0] == nil
+	l.checkLogSize = rc.checkLogSize
+	l.flags = rc.flags
+	l.level = rc.level
+	l.levels = rc.levels
+	l.maxLogSize = rc.maxLogSize
+	l.initializeLevelMaxWidth()
+	// rc was validated by resolve, or taken from l.
+	rules, _ := parseVModule(rc.levels, rc.vmodule)
+	l.setVModule(rc.vmodule, rules)
+	l.timeLayout, l.timeLocation = rc.timeLayout, rc.timeLocation
+
+	if shutDown {
+		l.filePath = rc.path
+		l.loggers = make([]*log.Logger, len(l.levels))
+		return nil
+	}
+	if rc.path == l.filePath {
+		l.initializeLoggers()
+		return nil
+	}
+
+	if err := l.shutdown(); err != nil {
+		return err
+	}
+	l.filePath = rc.path
+	if l.filePath != "" {
+		if err := os.MkdirAll(filepath.Dir(l.filePath), 0755); err != nil {
+			l.filePath = ""
+			l.openFileAndInitialize()
+			return fmt.Errorf("creating log file directory, error:%v", err)
+		}
+		if err := l.initializeRotation(); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return l.openFileAndInitialize()
+}
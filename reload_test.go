@@ -0,0 +1,187 @@
+package logh
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWatchConfig tests that level and path changes are applied to the existing Logger
+// while another goroutine is writing, without dropping entries.
+func TestWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	log1 := filepath.Join(dir, "log1.txt")
+	log2 := filepath.Join(dir, "log2.txt")
+	cfgPath := filepath.Join(dir, "logh.json")
+	writeConfig := func(path string, level string) {
+		cfg := fmt.Sprintf(`{"loggers": {"watched": {"path": %q, "level": %q, "flags": []}}}`, path, level)
+		if err := ioutil.WriteFile(cfgPath, []byte(cfg), 0644); err != nil {
+			t.Fatalf("error writing config, error: %v", err)
+		}
+	}
+	writeConfig(log1, "error")
+	defer ShutdownAll()
+
+	var errs []error
+	var errsMutex sync.Mutex
+	stop, err := WatchConfig(cfgPath, 5*time.Millisecond, func(err error) {
+		errsMutex.Lock()
+		errs = append(errs, err)
+		errsMutex.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("error with WatchConfig, error: %v", err)
+	}
+	defer stop()
+
+	defaultRegistry.mu.RLock()
+	lg := Map["watched"]
+	defaultRegistry.mu.RUnlock()
+
+	done := make(chan struct{})
+	written := make(chan int)
+	go func() {
+		n := 0
+		for {
+			select {
+			case <-done:
+				written <- n
+				return
+			default:
+			}
+			lg.Printf(Error, "entry %d", n)
+			n++
+		}
+	}()
+
+	writeConfig(log2, "info")
+	waitFor(t, func() bool { return lg.Enabled(Info) })
+	close(done)
+	n := <-written
+	lg.Printf(Error, "entry after reload")
+	n++
+	stop()
+
+	defaultRegistry.mu.RLock()
+	if Map["watched"] != lg {
+		t.Errorf("Logger pointer changed on reload")
+	}
+	defaultRegistry.mu.RUnlock()
+
+	lg.Shutdown()
+	l1, _ := readTestLog(log1, 0)
+	l2, _ := readTestLog(log2, 0)
+	if len(l2) == 0 {
+		t.Errorf("no entries written to new path")
+	}
+	all := strings.Split(strings.TrimSpace(l1+l2), "\n")
+	if len(all) != n {
+		t.Errorf("entries dropped, written: %d, logged: %d", n, len(all))
+	}
+
+	errsMutex.Lock()
+	defer errsMutex.Unlock()
+	if len(errs) > 0 {
+		t.Errorf("unexpected reload errors: %v", errs)
+	}
+}
+
+// TestWatchConfigError tests that an invalid change is reported and the prior configuration
+// is kept.
+func TestWatchConfigError(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "logh.json")
+	if err := ioutil.WriteFile(cfgPath, []byte(`{"loggers": {"watched": {"level": "error"}}}`), 0644); err != nil {
+		t.Fatalf("error writing config, error: %v", err)
+	}
+	defer ShutdownAll()
+
+	reported := make(chan error, 10)
+	stop, err := WatchConfig(cfgPath, 5*time.Millisecond, func(err error) { reported <- err })
+	if err != nil {
+		t.Fatalf("error with WatchConfig, error: %v", err)
+	}
+	defer stop()
+
+	if err := ioutil.WriteFile(cfgPath, []byte(`{"loggers": {"watched": {"level": "verbose"}}}`), 0644); err != nil {
+		t.Fatalf("error writing config, error: %v", err)
+	}
+	select {
+	case err := <-reported:
+		if !strings.Contains(err.Error(), "logger:watched, field:level") {
+			t.Errorf("incorrect error, error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("invalid config not reported")
+	}
+
+	defaultRegistry.mu.RLock()
+	lg := Map["watched"]
+	defaultRegistry.mu.RUnlock()
+	if lg.Enabled(Warning) || !lg.Enabled(Error) {
+		t.Errorf("prior configuration not kept")
+	}
+}
+
+// TestWatchConfigInterval tests that an interval of 0 or less is an error, rather than a
+// panic in the polling goroutine.
+func TestWatchConfigInterval(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "logh.json")
+	if err := ioutil.WriteFile(cfgPath, []byte(`{"loggers": {"watched": {}}}`), 0644); err != nil {
+		t.Fatalf("error writing config, error: %v", err)
+	}
+	defer ShutdownAll()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if stop, err := WatchConfig(cfgPath, interval, nil); err == nil {
+			stop()
+			t.Errorf("no error for interval: %s", interval)
+		}
+	}
+	if Lookup("watched") != nil {
+		t.Errorf("config applied with an invalid interval")
+	}
+}
+
+// TestReconfigureShutDown tests that applying a config to a shut down logger keeps it shut
+// down, rather than writing to the Registry output.
+func TestReconfigureShutDown(t *testing.T) {
+	var output bytes.Buffer
+	r := NewRegistry(&output)
+	path := filepath.Join(t.TempDir(), "stopped.txt")
+	if err := r.New("stopped", path, DefaultLevels, Debug, 0, 10, 10000); err != nil {
+		t.Fatalf("error with New, error: %v", err)
+	}
+	defer r.ShutdownAll()
+	lg := r.Get("stopped")
+	lg.Println(Info, "before")
+	lg.Shutdown()
+
+	err := r.ApplyConfig(Config{Loggers: map[string]LoggerConfig{
+		"stopped": {Path: path, Levels: []string{"low", "high"}},
+	}})
+	if err != nil {
+		t.Errorf("error with ApplyConfig, error: %v", err)
+	}
+	lg.Println(1, "after")
+
+	b, _ := ioutil.ReadFile(path + ".0")
+	if string(b) != "info: before\n" || output.Len() != 0 {
+		t.Errorf("shut down logger wrote, file: %q, output: %q", b, output.String())
+	}
+}
+
+// waitFor polls cond until it is true, failing the test after a timeout.
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,94 @@
+package logh
+
+import (
+	"fmt"
+	"time"
+)
+
+// sampler holds the SetSampling policy and the counts for the current interval.
+type sampler struct {
+	first      int
+	thereafter int
+	interval   time.Duration
+
+	// end is the end of the current interval.
+	end time.Time
+	// counts are the entries seen this interval, by level and message.
+	counts map[sampleKey]int
+	// dropped are the entries sampled out this interval, by level.
+	dropped map[LoghLevel]int
+}
+
+type sampleKey struct {
+	level LoghLevel
+	key   string
+}
+
+// SetSampling limits the entries written for each level and message, per interval: the
+// first entries are written, then every thereafter'th entry; thereafter 0 drops the rest.
+// The message is the format for Printf, msg for Printw and OutputDepth, and the first
+// operand, if a string, for Print and Println. Entries are sampled after the level filter
+// and before formatting, so sampled out entries cost no formatting.
+// The number of entries sampled out during an interval is written, at each level, before
+// the first entry written after the interval ends, and at Sync, Shutdown and reloads.
+// A first of 0 or less, or an interval of 0 or less, removes sampling.
+func (l *Logger) SetSampling(first int, thereafter int, interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushSampled()
+	if first <= 0 || interval <= 0 {
+		l.sampler = nil
+		return
+	}
+	l.sampler = &sampler{first: first, thereafter: thereafter, interval: interval}
+}
+
+// sample returns true if the entry should be written; l.mu must be held. Reports of
+// sampled out entries from the prior interval are written first, attributed to the call
+// site; skip is the printCommon skip plus the number of frames between sample and
+// printCommon.
+func (l *Logger) sample(skip int, level LoghLevel, key string) bool {
+	s := l.sampler
+	if s == nil {
+		return true
+	}
+
+	now := l.now()
+	if !now.Before(s.end) {
+		// The frames above reportSampled are sample, the skip frames, printCommon and the
+		// print function.
+		l.reportSampled(4 + skip)
+		s.end = now.Add(s.interval)
+		s.counts = map[sampleKey]int{}
+	}
+
+	k := sampleKey{level, key}
+	s.counts[k]++
+	n := s.counts[k]
+	if n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0) {
+		return true
+	}
+	s.dropped[level]++
+	return false
+}
+
+// flushSampled writes the number of entries sampled out so far in the current interval,
+// so they are not lost at Shutdown or Sync; l.mu must be held.
+func (l *Logger) flushSampled() {
+	l.reportSampled(2)
+}
+
+// reportSampled writes, then clears, the number of entries sampled out at each level.
+// calldepth is as for log.Output, from the caller of reportSampled. l.mu must be held.
+func (l *Logger) reportSampled(calldepth int) {
+	s := l.sampler
+	if s == nil {
+		return
+	}
+	for level := range l.levels {
+		if n := s.dropped[LoghLevel(level)]; n > 0 && level < len(l.loggers) && l.loggers[level] != nil {
+			l.emit(calldepth+1, LoghLevel(level), fmt.Sprintf("sampled out %d entries in %s", n, s.interval))
+		}
+	}
+	s.dropped = map[LoghLevel]int{}
+}
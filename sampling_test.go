@@ -0,0 +1,86 @@
+package logh
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSampling tests the first and thereafter counts per level and message, and the report
+// of sampled out entries.
+func TestSampling(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, log.Lshortfile, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	clock := &testClock{now: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)}
+	Map[loggerName].SetClock(clock)
+	Map[loggerName].SetSampling(2, 3, 50*time.Millisecond)
+
+	for i := 1; i <= 10; i++ {
+		Map[loggerName].Printf(Debug, "hot loop %d", i)
+		Map[loggerName].Printf(Info, "hot loop %d", i)
+		Map[loggerName].Println(Debug, "other", i)
+		clock.advance(time.Millisecond)
+	}
+	clock.advance(50 * time.Millisecond)
+	_, _, line, _ := runtime.Caller(0)
+	Map[loggerName].Printf(Debug, "next interval")
+	Map[loggerName].Shutdown()
+
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	for _, prefix := range []string{"debug: ", "info: "} {
+		written := []int{}
+		for _, v := range strings.Split(logString, "\n") {
+			var i int
+			if strings.HasPrefix(v, prefix) && strings.Contains(v, "hot loop") {
+				fmt.Sscanf(v[strings.Index(v, "hot loop"):], "hot loop %d", &i)
+				written = append(written, i)
+			}
+		}
+		if fmt.Sprint(written) != "[1 2 5 8]" {
+			t.Errorf("incorrect entries sampled, prefix: %s, written: %v", prefix, written)
+		}
+	}
+	if strings.Count(logString, "other") != 4 {
+		t.Errorf("message keys not independent")
+	}
+	// 6 per message: 12 debug, 6 info.
+	if !strings.Contains(logString, fmt.Sprintf("debug: sampling_test.go:%d: sampled out 12 entries in 50ms\n", line+1)) ||
+		!strings.Contains(logString, fmt.Sprintf("info: sampling_test.go:%d: sampled out 6 entries in 50ms\n", line+1)) ||
+		!strings.HasSuffix(logString, "next interval\n") {
+		t.Errorf("sampled out entries not reported")
+	}
+}
+
+// TestSamplingFlush tests that entries sampled out in the last interval are reported at
+// Sync and Shutdown.
+func TestSamplingFlush(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetSampling(1, 0, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		lg.Println(Info, "x")
+	}
+	lg.Sync()
+	lg.Println(Info, "x")
+	lg.Shutdown()
+
+	expected := "info: x\n" +
+		"info: sampled out 2 entries in 1m0s\n" +
+		"info: sampled out 1 entries in 1m0s\n"
+	logString, _ := readTestLog(testLog, 0)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
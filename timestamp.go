@@ -0,0 +1,89 @@
+package logh
+
+import (
+	"log"
+	"time"
+)
+
+// timeFlags are the log flags replaced by the SetTimeFormat timestamp.
+const timeFlags = log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC
+
+var (
+	// TimeFormatNames maps the names accepted in LoggerConfig.TimeFormat to layouts.
+	TimeFormatNames = map[string]string{
+		"RFC3339":     time.RFC3339,
+		"RFC3339Nano": time.RFC3339Nano,
+		"RFC1123Z":    time.RFC1123Z,
+		"StampMicro":  time.StampMicro,
+	}
+)
+
+// SetTimeFormat writes entry timestamps with the time.Format layout, in loc, in place of
+// the date and time from the log flags. A nil loc uses UTC if the flags include log.LUTC,
+// otherwise local time. An empty layout restores the timestamp set by the flags.
+//
+//	l.SetTimeFormat(time.RFC3339Nano, time.UTC)
+//	// info: 2021-04-01T15:43:24.617769123Z logh_test.go:194: started
+func (l *Logger) SetTimeFormat(layout string, loc *time.Location) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.setTimeFormat(layout, loc)
+}
+
+// setTimeFormat implements SetTimeFormat; l.mu must be held.
+func (l *Logger) setTimeFormat(layout string, loc *time.Location) {
+	l.timeLayout, l.timeLocation = layout, loc
+	for i, lg := range l.loggers {
+		if lg != nil {
+			lg.SetFlags(l.logFlags())
+			lg.SetPrefix(l.levels[i] + ": ")
+		}
+	}
+}
+
+// formatsTime returns true if l writes the timestamp in the prefix, rather than the log
+// package from the flags; l.mu must be held.
+func (l *Logger) formatsTime() bool {
+	return l.timeLayout != "" || l.clock != nil
+}
+
+// logFlags returns the flags for the log.Loggers, without the date and time flags when
+// l writes the timestamp.
+func (l *Logger) logFlags() int {
+	if !l.formatsTime() {
+		return l.flags
+	}
+	return l.flags &^ timeFlags
+}
+
+// prefix returns the prefix for an entry at level, including the timestamp if l writes
+// it.
+func (l *Logger) prefix(level LoghLevel) string {
+	if !l.formatsTime() {
+		return l.levels[level] + ": "
+	}
+	layout := l.timeLayout
+	if layout == "" {
+		if layout = flagsLayout(l.flags); layout == "" {
+			return l.levels[level] + ": "
+		}
+	}
+	loc := l.timeLocation
+	if loc == nil {
+		loc = time.Local
+		if l.flags&log.LUTC != 0 {
+			loc = time.UTC
+		}
+	}
+	return l.levels[level] + ": " + l.now().In(loc).Format(layout) + " "
+}
+
+// emit writes entry at level. calldepth is as for log.Output, from the caller of emit.
+// l.mu must be held.
+func (l *Logger) emit(calldepth int, level LoghLevel, entry string) {
+	lg := l.loggers[level]
+	if l.formatsTime() {
+		lg.SetPrefix(l.prefix(level))
+	}
+	lg.Output(calldepth+1, entry)
+}
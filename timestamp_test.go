@@ -0,0 +1,61 @@
+package logh
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// TestTimeFormat tests that the layout and location replace the timestamp from the
+// flags, for written and flight recorded entries, and that an empty layout restores it.
+func TestTimeFormat(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Info, DefaultFlags, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetFlightRecorder(1, Error)
+
+	lg.SetTimeFormat(time.RFC3339Nano, nil)
+	lg.Println(Info, "utc")
+	lg.SetTimeFormat("2006-01-02T15:04:05.000-07:00", time.FixedZone("field", 2*60*60))
+	lg.Println(Debug, "recorded")
+	lg.Println(Error, "offset")
+	lg.SetTimeFormat("", nil)
+	lg.Println(Info, "flags")
+	lg.Shutdown()
+
+	expected := regexp.MustCompile(`^info: \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(\.\d+)?Z timestamp_test.go:\d+: utc
+debug: \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}\+02:00 timestamp_test.go:\d+: recorded
+error: \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}\+02:00 timestamp_test.go:\d+: offset
+info: \d{4}/\d\d/\d\d \d\d:\d\d:\d\d\.\d{6} timestamp_test.go:\d+: flags
+$`)
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	if !expected.MatchString(logString) {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
+
+// TestTimeFormatLocal tests that a nil location uses local time when the flags do not
+// include log.LUTC.
+func TestTimeFormatLocal(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Info, log.Ldate, 10, 100000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+	lg := Map[loggerName]
+	lg.SetTimeFormat("-07:00", nil)
+	lg.Println(Info, "local")
+	lg.Shutdown()
+
+	expected := "info: " + time.Now().Format("-07:00") + " local\n"
+	logString, _ := readTestLog(testLog, 0)
+	if logString != expected {
+		t.Errorf("incorrect output, received:\n%s\nexpected:\n%s", logString, expected)
+	}
+}
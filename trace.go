@@ -0,0 +1,116 @@
+package logh
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// SpanContext is implemented by callers that already have trace information, so that
+// logh does not need to depend on any particular tracing library. For example, an
+// OpenTelemetry span context can be adapted by returning
+// SpanContext().TraceID().String() and SpanContext().SpanID().String().
+type SpanContext interface {
+	TraceID() string
+	SpanID() string
+}
+
+// traceparent is a parsed W3C traceparent header value, and satisfies SpanContext.
+type traceparent struct {
+	traceID string
+	spanID  string
+}
+
+type contextKey int
+
+const (
+	spanContextKey contextKey = iota
+)
+
+// ContextWithSpanContext returns a copy of ctx carrying sc. Loggers called with the
+// *Context print functions will emit the trace_id and span_id fields from sc.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey, sc)
+}
+
+// ContextWithTraceparent returns a copy of ctx carrying the trace information from a
+// W3C traceparent header value (https://www.w3.org/TR/trace-context/#traceparent-header).
+// An error is returned, and ctx returned unchanged, if the value cannot be parsed.
+func ContextWithTraceparent(ctx context.Context, value string) (context.Context, error) {
+	tp, err := parseTraceparent(value)
+	if err != nil {
+		return ctx, err
+	}
+	return ContextWithSpanContext(ctx, tp), nil
+}
+
+// PrintContext is Print, with trace_id and span_id fields added when ctx carries a
+// SpanContext.
+func (l *Logger) PrintContext(ctx context.Context, level LoghLevel, v ...interface{}) {
+	l.printCommon(0, level, printKey(v), traceFields(ctx), sprint(v))
+}
+
+// PrintfContext is Printf, with trace_id and span_id fields added when ctx carries a
+// SpanContext.
+func (l *Logger) PrintfContext(ctx context.Context, level LoghLevel, format string, v ...interface{}) {
+	l.printCommon(0, level, format, traceFields(ctx), sprintf(format, v))
+}
+
+// PrintlnContext is Println, with trace_id and span_id fields added when ctx carries a
+// SpanContext.
+func (l *Logger) PrintlnContext(ctx context.Context, level LoghLevel, v ...interface{}) {
+	l.printCommon(0, level, printKey(v), traceFields(ctx), sprintln(v))
+}
+
+// PrintwContext is Printw, with trace_id and span_id fields added when ctx carries a
+// SpanContext.
+func (l *Logger) PrintwContext(ctx context.Context, level LoghLevel, msg string, keysAndValues ...interface{}) {
+	l.printCommon(0, level, msg, append(keyValueFields(keysAndValues), traceFields(ctx)...), func() string { return msg })
+}
+
+func (tp traceparent) TraceID() string {
+	return tp.traceID
+}
+
+func (tp traceparent) SpanID() string {
+	return tp.spanID
+}
+
+// parseTraceparent parses version-traceid-parentid-flags. Unknown (future) versions are
+// accepted as long as the leading fields are well formed, per the W3C specification.
+func parseTraceparent(value string) (traceparent, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return traceparent{}, fmt.Errorf("invalid traceparent, value:%s", value)
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) ||
+		!isLowerHex(traceID, 32) || !isLowerHex(spanID, 16) || !isLowerHex(flags, 2) {
+		return traceparent{}, fmt.Errorf("invalid traceparent, value:%s", value)
+	}
+	if traceID == strings.Repeat("0", 32) || spanID == strings.Repeat("0", 16) {
+		return traceparent{}, fmt.Errorf("invalid traceparent, all zero id, value:%s", value)
+	}
+	return traceparent{traceID: traceID, spanID: spanID}, nil
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// traceFields returns the trace_id and span_id fields for ctx, or nil if there are none.
+func traceFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	sc, ok := ctx.Value(spanContextKey).(SpanContext)
+	if !ok || sc == nil || sc.TraceID() == "" {
+		return nil
+	}
+	return []Field{{"trace_id", sc.TraceID()}, {"span_id", sc.SpanID()}}
+}
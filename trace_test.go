@@ -0,0 +1,68 @@
+package logh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testSpan struct{}
+
+func (testSpan) TraceID() string { return "trace-from-interface" }
+func (testSpan) SpanID() string  { return "span-from-interface" }
+
+// TestTraceFields tests that trace_id and span_id are emitted from a traceparent, or from a
+// caller supplied SpanContext, and not emitted without one.
+func TestTraceFields(t *testing.T) {
+	testSetup(t)
+	err := New(loggerName, testLog, DefaultLevels, Debug, 0, 10, 10000)
+	if err != nil {
+		t.Errorf("error with New, error: %v", err)
+	}
+
+	ctx, err := ContextWithTraceparent(context.Background(),
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Errorf("error with ContextWithTraceparent, error: %v", err)
+	}
+	Map[loggerName].PrintfContext(ctx, Info, "in span %d", 1)
+	Map[loggerName].PrintlnContext(ContextWithSpanContext(context.Background(), testSpan{}), Info, "in span 2")
+	Map[loggerName].PrintfContext(context.Background(), Info, "no span")
+	Map[loggerName].Shutdown()
+
+	logString, _ := readTestLog(testLog, 0)
+	fmt.Println(logString)
+	if !strings.Contains(logString, "info: in span 1 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7\n") ||
+		!strings.Contains(logString, "info: in span 2 trace_id=trace-from-interface span_id=span-from-interface\n") ||
+		!strings.Contains(logString, "info: no span\n") {
+		t.Errorf("trace fields incorrect, log: %s", logString)
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	valid := []string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future",
+	}
+	for _, v := range valid {
+		if _, err := parseTraceparent(v); err != nil {
+			t.Errorf("valid traceparent rejected, value: %s, error: %v", v, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+	for _, v := range invalid {
+		if _, err := parseTraceparent(v); err == nil {
+			t.Errorf("invalid traceparent accepted, value: %s", v)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package logh
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// vmoduleRule sets level for call sites in files matching pattern.
+type vmoduleRule struct {
+	pattern string
+	// components is the number of trailing path components of a file compared to pattern.
+	components int
+	level      LoghLevel
+}
+
+// SetVModule sets per file or per package level overrides for l, similar to glog vmodule.
+// spec is a comma separated list of pattern=level, where level is a name from the levels
+// of l. A pattern without a / is matched against the file name, and a pattern with a /
+// against that many trailing path components, using path.Match; a trailing .go is
+// optional. The first matching rule sets the level for the call site, otherwise the level
+// of l applies. An empty spec removes all overrides.
+//
+//	l.SetVModule("storage/*=debug,main=warning")
+//
+// The file for each call site is resolved once and cached, so entries only pay for the
+// caller lookup while overrides are set.
+func (l *Logger) SetVModule(spec string) error {
+	rules, err := parseVModule(l.currentLevels(), spec)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.setVModule(spec, rules)
+	return nil
+}
+
+func (l *Logger) currentLevels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.levels
+}
+
+// setVModule sets the rules and clears the cache; l.mu must be held.
+func (l *Logger) setVModule(spec string, rules []vmoduleRule) {
+	l.vmodule = rules
+	l.vmoduleSpec = spec
+	l.vmoduleCache = nil
+	if len(rules) > 0 {
+		l.vmoduleCache = map[uintptr]int{}
+	}
+}
+
+// parseVModule parses a SetVModule spec, resolving level names against levels.
+func parseVModule(levels []string, spec string) ([]vmoduleRule, error) {
+	var rules []vmoduleRule
+	for _, v := range strings.Split(spec, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid vmodule rule:%s", v)
+		}
+		pattern := strings.TrimSuffix(kv[0], ".go")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid vmodule pattern:%s, error:%v", kv[0], err)
+		}
+		level, ok := levelIndex(levels, kv[1])
+		if !ok {
+			return nil, fmt.Errorf("invalid vmodule rule:%s, unknown level:%s, levels:%s",
+				v, kv[1], strings.Join(levels, ","))
+		}
+		rules = append(rules, vmoduleRule{
+			pattern:    pattern,
+			components: strings.Count(pattern, "/") + 1,
+			level:      level,
+		})
+	}
+	return rules, nil
+}
+
+// threshold returns the level at or above which entries are written, for the call site
+// skip frames above threshold; skip 1 is the function calling threshold. l.mu must be held.
+func (l *Logger) threshold(skip int) LoghLevel {
+	if len(l.vmodule) == 0 {
+		return l.level
+	}
+
+	pcs := [1]uintptr{}
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return l.level
+	}
+	rule, ok := l.vmoduleCache[pcs[0]]
+	if !ok {
+		frame, _ := runtime.CallersFrames(pcs[:]).Next()
+		rule = l.matchVModule(frame.File)
+		l.vmoduleCache[pcs[0]] = rule
+	}
+	if rule < 0 {
+		return l.level
+	}
+	return l.vmodule[rule].level
+}
+
+// matchVModule returns the index of the first rule matching file, or -1.
+func (l *Logger) matchVModule(file string) int {
+	file = strings.TrimSuffix(file, ".go")
+	parts := strings.Split(file, "/")
+	for i, r := range l.vmodule {
+		if r.components > len(parts) {
+			continue
+		}
+		name := strings.Join(parts[len(parts)-r.components:], "/")
+		if ok, _ := path.Match(r.pattern, name); ok {
+			return i
+		}
+	}
+	return -1
+}
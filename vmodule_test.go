@@ -0,0 +1,61 @@
+package logh
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestVModule tests that overrides apply only to matching call sites, for both Printf and
+// Enabled.
+func TestVModule(t *testing.T) {
+	tests := []struct {
+		spec     string
+		override bool
+	}{
+		{"vmodule_test=debug", true},
+		{"vmodule_test.go=debug", true},
+		{"*/vmodule_test=debug", true},
+		{"vmod*=debug", true},
+		{"other=error,vmodule_test=debug", true},
+		{"other=debug", false},
+		{"", false},
+	}
+
+	for _, v := range tests {
+		testSetup(t)
+		err := New(loggerName, testLog, DefaultLevels, Warning, 0, 10, 10000)
+		if err != nil {
+			t.Errorf("error with New, error: %v", err)
+		}
+		if err := Map[loggerName].SetVModule(v.spec); err != nil {
+			t.Errorf("error with SetVModule, spec: %s, error: %v", v.spec, err)
+		}
+
+		if Map[loggerName].Enabled(Debug) != v.override {
+			t.Errorf("Enabled incorrect, spec: %s", v.spec)
+		}
+		for i := 0; i < 2; i++ {
+			// Twice, so the second uses the cache.
+			Map[loggerName].Printf(Debug, "from this file %d", i)
+		}
+		// testPrints are logged from logh_test.go, which uses the logger level.
+		testPrints[Info].Println(t)
+		Map[loggerName].Shutdown()
+
+		logString, _ := readTestLog(testLog, 0)
+		fmt.Printf("spec: %s\n%s", v.spec, logString)
+		if strings.Contains(logString, "from this file 1") != v.override {
+			t.Errorf("override incorrect, spec: %s", v.spec)
+		}
+		if strings.Contains(logString, "info print") {
+			t.Errorf("override applied to non-matching file, spec: %s", v.spec)
+		}
+	}
+
+	for _, spec := range []string{"vmodule_test", "=debug", "vmodule_test=verbose", "[=debug"} {
+		if err := Map[loggerName].SetVModule(spec); err == nil {
+			t.Errorf("invalid spec accepted: %s", spec)
+		}
+	}
+}